@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/config"
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/domain"
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/health"
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/repository"
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/service"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/calendar"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/database"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/logger"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
+)
+
+const migrationsPath = "migrations"
+
+// app holds the dependencies shared by all commands
+type app struct {
+	cfg             *config.Config
+	db              *pgxpool.Pool
+	storage         domain.Repository
+	svc             *service.Service
+	calendar        *calendar.Calendar
+	shutdownTracing func(context.Context) error
+}
+
+// newApp loads the configuration, connects to the database and applies migrations
+func newApp(ctx context.Context) (*app, error) {
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Set up logging
+	logger.SetupLogging(cfg.App.LogLevel)
+
+	// Set up the working calendar for business time durations
+	cal, err := cfg.GetCalendar()
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up calendar: %w", err)
+	}
+
+	// Set up tracing
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing.Endpoint, cfg.Tracing.ServiceName, cfg.Tracing.Insecure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up tracing: %w", err)
+	}
+
+	// Connect to database
+	db, err := database.Connect(cfg.GetDSN())
+	if err != nil {
+		shutdownTracing(context.WithoutCancel(ctx))
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Run migrations
+	if err := database.MigrateDB(cfg.GetMigrateDSN(), migrationsPath); err != nil {
+		db.Close()
+		shutdownTracing(context.WithoutCancel(ctx))
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	// Create repository
+	repositoryService := repository.NewService(db, cfg.Tracker.OrgID)
+
+	return &app{
+		cfg:             cfg,
+		db:              db,
+		storage:         repositoryService,
+		svc:             service.NewService(cfg, repositoryService, cal),
+		calendar:        cal,
+		shutdownTracing: shutdownTracing,
+	}, nil
+}
+
+// Close releases the database connection and flushes pending spans
+func (a *app) Close(ctx context.Context) {
+	a.db.Close()
+	if err := a.shutdownTracing(ctx); err != nil {
+		slog.Error("Failed to shut down tracing", "error", err)
+	}
+}
+
+// sync runs a single sync and pushes its metrics if a Pushgateway is configured
+func (a *app) sync(ctx context.Context) (*domain.RunReport, error) {
+	report, err := a.svc.Sync(ctx)
+	if a.cfg.Metrics.PushgatewayURL != "" {
+		if err := metrics.Push(context.WithoutCancel(ctx), a.cfg.Metrics.PushgatewayURL, a.cfg.Metrics.JobName); err != nil {
+			slog.Error("Failed to push metrics", "error", err)
+		}
+	}
+	if err != nil {
+		return report, fmt.Errorf("failed to sync data (run %s): %w", report.RunID, err)
+	}
+	return report, nil
+}
+
+// daemon runs a sync every SYNC_INTERVAL until ctx is cancelled
+func (a *app) daemon(ctx context.Context) error {
+	if a.cfg.App.SyncInterval <= 0 {
+		return fmt.Errorf("SYNC_INTERVAL must be positive in daemon mode")
+	}
+
+	slog.Info("Starting daemon", "sync_interval", a.cfg.App.SyncInterval.String())
+
+	ticker := time.NewTicker(a.cfg.App.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := a.svc.Sync(ctx); err != nil {
+			slog.Error("Failed to sync data", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Stopping daemon")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// serveHTTP starts the HTTP listener with metrics and health endpoints in the background
+func (a *app) serveHTTP() (*http.Server, error) {
+	migrationVersion, err := database.LatestMigrationVersion(migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+	checker := health.NewChecker(a.db, a.storage, a.svc.GetTracker(), migrationVersion, a.cfg.GetMaxSyncAge())
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.HandleFunc("/healthz", checker.Healthz)
+	mux.HandleFunc("/readyz", checker.Readyz)
+
+	srv := &http.Server{
+		Addr:              a.cfg.App.HTTPAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		slog.Info("Starting HTTP listener", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("HTTP listener failed", "error", err)
+		}
+	}()
+
+	return srv, nil
+}
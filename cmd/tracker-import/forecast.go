@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"time"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/forecast"
+)
+
+// forecast predicts when a backlog will be done from the weekly throughput of
+// the imported issues.
+// Usage: tracker-import forecast (-count N | -query QUERY) [-queue KEY] [-weeks N] [-trials N] [-format text|json]
+func (a *app) forecast(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("forecast", flag.ContinueOnError)
+	count := fs.Int("count", 0, "number of backlog issues")
+	query := fs.String("query", "", "Tracker query selecting the backlog issues, e.g. \"Queue: DEV Resolution: empty()\"")
+	queueKey := fs.String("queue", "", "queue to take the throughput history from (default all queues)")
+	weeks := fs.Int("weeks", 12, "number of past complete weeks used as throughput history")
+	trials := fs.Int("trials", 10000, "number of simulation runs")
+	seed := fs.Uint64("seed", 0, "random seed for reproducible results (default random)")
+	format := fs.String("format", forecast.FormatText, "output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (*count > 0) == (*query != "") || fs.NArg() != 0 {
+		return fmt.Errorf("usage: tracker-import forecast (-count N | -query QUERY) [-queue KEY] [-weeks N] [-trials N] [-format text|json]")
+	}
+	if *weeks <= 0 {
+		return fmt.Errorf("-weeks must be positive")
+	}
+
+	backlog := *count
+	if *query != "" {
+		refs, err := a.svc.GetTracker().GetIssueRefs(ctx, *query)
+		if err != nil {
+			return fmt.Errorf("failed to count backlog issues: %w", err)
+		}
+		if len(refs) == 0 {
+			return fmt.Errorf("no issues match query %q", *query)
+		}
+		backlog = len(refs)
+	}
+
+	now := time.Now().In(a.calendar.Location())
+	historyTo := forecast.WeekStart(now)
+	historyFrom := historyTo.AddDate(0, 0, -7*(*weeks))
+	completions, err := a.storage.GetCompletionTimes(ctx, *queueKey, historyFrom)
+	if err != nil {
+		return err
+	}
+	throughput := forecast.WeeklyThroughput(completions, historyFrom, historyTo)
+
+	rng := rand.New(rand.NewPCG(*seed, *seed))
+	if *seed == 0 {
+		rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	results, err := forecast.Simulate(throughput, backlog, *trials, rng)
+	if err != nil {
+		return err
+	}
+
+	start := now.Format(time.DateOnly)
+	return forecast.Render(stdout, *format, forecast.Forecast{
+		Backlog:          backlog,
+		Query:            *query,
+		QueueKey:         *queueKey,
+		HistoryFrom:      historyFrom.Format(time.DateOnly),
+		HistoryTo:        historyTo.AddDate(0, 0, -1).Format(time.DateOnly),
+		WeeklyThroughput: throughput,
+		Trials:           *trials,
+		Start:            start,
+		Results:          forecast.PercentileResults(results, now),
+	})
+}
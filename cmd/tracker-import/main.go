@@ -2,106 +2,103 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 
-	"github.com/nemirlev/yc-tracker-go-data-import/internal/config"
-	"github.com/nemirlev/yc-tracker-go-data-import/internal/repository"
-	"github.com/nemirlev/yc-tracker-go-data-import/internal/service"
-	"github.com/nemirlev/yc-tracker-go-data-import/pkg/database"
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/domain"
 	"github.com/nemirlev/yc-tracker-go-data-import/pkg/logger"
 )
 
+// Usage: tracker-import [sync|daemon|workflow|forecast|stale] [args]. Without a command a single sync is run.
 func main() {
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		slog.Error("Failed to load configuration", "error", err)
-		os.Exit(1)
-	}
-
-	// Set up logging
-	logger.SetupLogging(cfg.App.LogLevel)
-
-	// Connect to database
-	db, err := database.Connect(cfg.GetDSN())
-	if err != nil {
-		slog.Error("Failed to connect to database", "error", err)
-		os.Exit(1)
+	command := "sync"
+	var args []string
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+		args = os.Args[2:]
 	}
-	defer db.Close()
-
-	// Run migrations
-	migrationsPath := "migrations"
-	if err := database.MigrateDB(cfg.GetMigrateDSN(), migrationsPath); err != nil {
-		slog.Error("Failed to run migrations", "error", err)
-		os.Exit(1)
-	}
-
-	// Create repository
-	repositoryService := repository.NewService(db)
-
-	// Create main service
-	svc := service.NewService(cfg, repositoryService)
-
-	// Create context with cancellation
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
 
 	// Handle graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// Run sync operation
-	if err := svc.Sync(ctx); err != nil {
-		slog.Error("Failed to sync data", "error", err)
+	if err := run(ctx, command, args); err != nil {
+		slog.Error("Application failed", "command", command, "error", err)
 		os.Exit(1)
 	}
 
-	slog.Info("Application completed successfully")
+	slog.Info("Application completed successfully", "command", command)
 }
 
-// Handler for Yandex Cloud Function
-func Handler(ctx context.Context) error {
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		slog.Error("Failed to load configuration", "error", err)
-		return err
+// run executes the given command
+func run(ctx context.Context, command string, args []string) error {
+	switch command {
+	case "sync", "daemon", "workflow", "forecast", "stale":
+	default:
+		return fmt.Errorf("unknown command %q", command)
 	}
 
-	// Set up logging
-	logger.SetupLogging(cfg.App.LogLevel)
+	// Report commands write to stdout, so logs go to stderr
+	if command == "workflow" || command == "forecast" || command == "stale" {
+		logger.SetOutput(os.Stderr)
+	}
 
-	// Connect to database
-	db, err := database.Connect(cfg.GetDSN())
+	a, err := newApp(ctx)
 	if err != nil {
-		slog.Error("Failed to connect to database", "error", err)
 		return err
 	}
-	defer db.Close()
+	defer a.Close(context.WithoutCancel(ctx))
+
+	// Report commands only read the database
+	switch command {
+	case "workflow":
+		return a.workflow(ctx, args, os.Stdout)
+	case "forecast":
+		return a.forecast(ctx, args, os.Stdout)
+	case "stale":
+		return a.stale(ctx, args, os.Stdout)
+	}
 
-	// Run migrations
-	migrationsPath := "migrations"
-	if err := database.MigrateDB(cfg.GetMigrateDSN(), migrationsPath); err != nil {
-		slog.Error("Failed to run migrations", "error", err)
-		return err
+	// Expose metrics and health endpoints if a listener address is configured
+	if a.cfg.App.HTTPAddr != "" {
+		srv, err := a.serveHTTP()
+		if err != nil {
+			return fmt.Errorf("failed to start HTTP listener: %w", err)
+		}
+		defer srv.Shutdown(context.WithoutCancel(ctx))
 	}
 
-	// Create repository
-	repositoryService := repository.NewService(db)
+	switch command {
+	case "daemon":
+		return a.daemon(ctx)
+	default:
+		report, err := a.sync(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("Sync finished", "report", report)
+		return nil
+	}
+}
 
-	// Create main service
-	svc := service.NewService(cfg, repositoryService)
+// Handler for Yandex Cloud Function. The run report is returned as the function response.
+func Handler(ctx context.Context) (*domain.RunReport, error) {
+	a, err := newApp(ctx)
+	if err != nil {
+		slog.Error("Failed to initialize application", "error", err)
+		return nil, err
+	}
+	defer a.Close(context.WithoutCancel(ctx))
 
-	// Run sync operation
-	if err := svc.Sync(ctx); err != nil {
+	report, err := a.sync(ctx)
+	if err != nil {
 		slog.Error("Failed to sync data", "error", err)
-		return err
+		return report, err
 	}
 
-	slog.Info("Application completed successfully")
-	return nil
+	slog.Info("Application completed successfully", "report", report)
+	return report, nil
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/domain"
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/notifier"
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/stale"
+)
+
+// stale lists in-progress issues whose time in the current status exceeds a
+// percentile of the historical time in that status for their queue.
+// Usage: tracker-import stale [-queue KEY] [-percentile N] [-min-samples N] [-format text|json] [-notify]
+func (a *app) stale(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("stale", flag.ContinueOnError)
+	queueKey := fs.String("queue", "", "queue to report (default all queues)")
+	percentile := fs.Int("percentile", 85, "percentile of the historical time in status used as the threshold")
+	minSamples := fs.Int("min-samples", 5, "minimum number of completed intervals in a status to report it")
+	format := fs.String("format", stale.FormatText, "output format: text or json")
+	notify := fs.Bool("notify", false, "post the report to STALE_WEBHOOKS instead of printing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: tracker-import stale [-queue KEY] [-percentile N] [-min-samples N] [-format text|json] [-notify]")
+	}
+	if *percentile <= 0 || *percentile >= 100 {
+		return fmt.Errorf("-percentile must be between 1 and 99")
+	}
+	if *notify && len(a.cfg.Stale.Webhooks) == 0 {
+		return fmt.Errorf("-notify requires STALE_WEBHOOKS")
+	}
+
+	issues, err := a.storage.GetStaleIssues(ctx, *queueKey, float64(*percentile)/100, *minSamples)
+	if err != nil {
+		return err
+	}
+	if issues == nil {
+		issues = []domain.StaleIssue{}
+	}
+
+	now := time.Now().In(a.calendar.Location())
+	report := stale.Report{
+		GeneratedAt: now.Format(time.RFC3339),
+		QueueKey:    *queueKey,
+		Percentile:  *percentile,
+		Issues:      issues,
+	}
+
+	if !*notify {
+		return stale.Render(stdout, *format, report)
+	}
+	dedupeKey := fmt.Sprintf("stale:%s:%s", *queueKey, now.Format(time.DateOnly))
+	return notifier.New(a.cfg).Post(ctx, a.cfg.Stale.Webhooks, dedupeKey, stale.Message(report), report)
+}
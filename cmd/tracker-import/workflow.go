@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/workflow"
+)
+
+// workflow renders the stored workflows of a queue.
+// Usage: tracker-import workflow [-format dot|mermaid] [-o file] QUEUE
+func (a *app) workflow(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("workflow", flag.ContinueOnError)
+	format := fs.String("format", workflow.FormatDOT, "output format: dot or mermaid")
+	output := fs.String("o", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: tracker-import workflow [-format dot|mermaid] [-o file] QUEUE")
+	}
+	queueKey := fs.Arg(0)
+
+	workflows, err := a.storage.GetWorkflows(ctx, queueKey)
+	if err != nil {
+		return fmt.Errorf("failed to get workflows of queue %s: %w", queueKey, err)
+	}
+	if len(workflows) == 0 {
+		return fmt.Errorf("no workflows stored for queue %s, run a sync first", queueKey)
+	}
+
+	if *output == "" {
+		return workflow.Render(stdout, *format, workflows)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := workflow.Render(f, *format, workflows); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -1,8 +1,15 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
 
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/calendar"
 	"github.com/spf13/viper"
 )
 
@@ -14,6 +21,7 @@ type Config struct {
 		OAuthToken          string `mapstructure:"TRACKER_OAUTH_TOKEN"`
 		InitialHistoryDepth string `mapstructure:"TRACKER_INITIAL_HISTORY_DEPTH"`
 		Filter              string `mapstructure:"TRACKER_FILTER"`
+		Reconcile           bool   `mapstructure:"TRACKER_RECONCILE"`
 	} `mapstructure:",squash"`
 	PostgreSQL struct {
 		Host     string `mapstructure:"PG_HOST"`
@@ -24,8 +32,57 @@ type Config struct {
 		SSLMode  string `mapstructure:"PG_SSLMODE"`
 	} `mapstructure:",squash"`
 	App struct {
-		LogLevel string `mapstructure:"LOG_LEVEL"`
+		LogLevel     string        `mapstructure:"LOG_LEVEL"`
+		HTTPAddr     string        `mapstructure:"HTTP_ADDR"`
+		MetricsAddr  string        `mapstructure:"METRICS_ADDR"` // deprecated alias of HTTP_ADDR
+		SyncInterval time.Duration `mapstructure:"SYNC_INTERVAL"`
+		MaxSyncAge   time.Duration `mapstructure:"HEALTH_MAX_SYNC_AGE"`
 	} `mapstructure:",squash"`
+	Metrics struct {
+		PushgatewayURL string `mapstructure:"METRICS_PUSHGATEWAY_URL"`
+		JobName        string `mapstructure:"METRICS_JOB_NAME"`
+	} `mapstructure:",squash"`
+	Notify struct {
+		On       string          `mapstructure:"NOTIFY_ON"`
+		Webhooks []WebhookConfig `mapstructure:"NOTIFY_WEBHOOKS"`
+	} `mapstructure:",squash"`
+	Stale struct {
+		Webhooks []WebhookConfig `mapstructure:"STALE_WEBHOOKS"`
+	} `mapstructure:",squash"`
+	Attachments struct {
+		Import            bool   `mapstructure:"ATTACHMENTS_IMPORT"`
+		Download          string `mapstructure:"ATTACHMENTS_DOWNLOAD"` // "", dir or s3
+		Dir               string `mapstructure:"ATTACHMENTS_DIR"`
+		S3Endpoint        string `mapstructure:"ATTACHMENTS_S3_ENDPOINT"`
+		S3Region          string `mapstructure:"ATTACHMENTS_S3_REGION"`
+		S3Bucket          string `mapstructure:"ATTACHMENTS_S3_BUCKET"`
+		S3Prefix          string `mapstructure:"ATTACHMENTS_S3_PREFIX"`
+		S3AccessKeyID     string `mapstructure:"ATTACHMENTS_S3_ACCESS_KEY_ID"`
+		S3SecretAccessKey string `mapstructure:"ATTACHMENTS_S3_SECRET_ACCESS_KEY"`
+	} `mapstructure:",squash"`
+	Worklogs struct {
+		Import bool `mapstructure:"WORKLOGS_IMPORT"`
+	} `mapstructure:",squash"`
+	Calendar struct {
+		TimeZone     string   `mapstructure:"CALENDAR_TIMEZONE"`
+		WorkStart    string   `mapstructure:"CALENDAR_WORK_START"`
+		WorkEnd      string   `mapstructure:"CALENDAR_WORK_END"`
+		Weekends     []string `mapstructure:"CALENDAR_WEEKENDS"`
+		HolidaysFile string   `mapstructure:"CALENDAR_HOLIDAYS_FILE"`
+	} `mapstructure:",squash"`
+	Tracing struct {
+		Endpoint    string `mapstructure:"TRACING_OTLP_ENDPOINT"`
+		Insecure    bool   `mapstructure:"TRACING_OTLP_INSECURE"`
+		ServiceName string `mapstructure:"TRACING_SERVICE_NAME"`
+	} `mapstructure:",squash"`
+}
+
+// WebhookConfig describes a notification webhook
+type WebhookConfig struct {
+	URL      string `mapstructure:"url"`
+	Format   string `mapstructure:"format"`   // json, telegram or slack
+	Template string `mapstructure:"template"` // optional text/template overriding the payload or message
+	ChatID   string `mapstructure:"chat_id"`  // Telegram chat ID
 }
 
 var cfg Config
@@ -36,9 +93,37 @@ func Load() (*Config, error) {
 	viper.AddConfigPath(".")
 
 	// Set defaults
+	viper.SetDefault("TRACKER_RECONCILE", true)
 	viper.SetDefault("PG_PORT", 5432)
 	viper.SetDefault("PG_SSLMODE", "disable")
 	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("HTTP_ADDR", "")
+	viper.SetDefault("METRICS_ADDR", "")
+	viper.SetDefault("SYNC_INTERVAL", "1h")
+	viper.SetDefault("HEALTH_MAX_SYNC_AGE", "0")
+	viper.SetDefault("METRICS_PUSHGATEWAY_URL", "")
+	viper.SetDefault("METRICS_JOB_NAME", "tracker_import")
+	viper.SetDefault("NOTIFY_ON", "failure")
+	viper.SetDefault("NOTIFY_WEBHOOKS", "")
+	viper.SetDefault("STALE_WEBHOOKS", "")
+	viper.SetDefault("ATTACHMENTS_IMPORT", false)
+	viper.SetDefault("ATTACHMENTS_DOWNLOAD", "")
+	viper.SetDefault("ATTACHMENTS_DIR", "attachments")
+	viper.SetDefault("ATTACHMENTS_S3_ENDPOINT", "https://storage.yandexcloud.net")
+	viper.SetDefault("ATTACHMENTS_S3_REGION", "ru-central1")
+	viper.SetDefault("ATTACHMENTS_S3_BUCKET", "")
+	viper.SetDefault("ATTACHMENTS_S3_PREFIX", "")
+	viper.SetDefault("ATTACHMENTS_S3_ACCESS_KEY_ID", "")
+	viper.SetDefault("ATTACHMENTS_S3_SECRET_ACCESS_KEY", "")
+	viper.SetDefault("WORKLOGS_IMPORT", false)
+	viper.SetDefault("CALENDAR_TIMEZONE", "Europe/Moscow")
+	viper.SetDefault("CALENDAR_WORK_START", "09:00")
+	viper.SetDefault("CALENDAR_WORK_END", "18:00")
+	viper.SetDefault("CALENDAR_WEEKENDS", []string{"saturday", "sunday"})
+	viper.SetDefault("CALENDAR_HOLIDAYS_FILE", "")
+	viper.SetDefault("TRACING_OTLP_ENDPOINT", "")
+	viper.SetDefault("TRACING_OTLP_INSECURE", false)
+	viper.SetDefault("TRACING_SERVICE_NAME", "tracker-import")
 
 	// Read environment variables
 	viper.AutomaticEnv()
@@ -50,10 +135,21 @@ func Load() (*Config, error) {
 		}
 	}
 
-	if err := viper.Unmarshal(&cfg); err != nil {
+	decodeHook := viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		jsonStringToSliceHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	))
+	if err := viper.Unmarshal(&cfg, decodeHook); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// METRICS_ADDR was renamed to HTTP_ADDR when health endpoints were added
+	if cfg.App.HTTPAddr == "" && cfg.App.MetricsAddr != "" {
+		slog.Warn("METRICS_ADDR is deprecated, use HTTP_ADDR instead")
+		cfg.App.HTTPAddr = cfg.App.MetricsAddr
+	}
+
 	// Validate required fields
 	if err := validateConfig(); err != nil {
 		return nil, err
@@ -62,6 +158,28 @@ func Load() (*Config, error) {
 	return &cfg, nil
 }
 
+// jsonStringToSliceHookFunc decodes JSON arrays passed as strings, e.g. lists
+// of objects set through environment variables
+func jsonStringToSliceHookFunc() mapstructure.DecodeHookFunc {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String || t.Kind() != reflect.Slice {
+			return data, nil
+		}
+		str := strings.TrimSpace(data.(string))
+		if str == "" {
+			return []interface{}{}, nil
+		}
+		if !strings.HasPrefix(str, "[") {
+			return data, nil
+		}
+		var items []interface{}
+		if err := json.Unmarshal([]byte(str), &items); err != nil {
+			return nil, fmt.Errorf("failed to decode JSON list: %w", err)
+		}
+		return items, nil
+	}
+}
+
 func validateConfig() error {
 	if cfg.Tracker.APIIssuesURL == "" {
 		return fmt.Errorf("TRACKER_API_ISSUES_URL is required")
@@ -84,9 +202,72 @@ func validateConfig() error {
 	if cfg.PostgreSQL.Password == "" {
 		return fmt.Errorf("PG_PASSWORD is required")
 	}
+	if cfg.Notify.On != "failure" && cfg.Notify.On != "always" {
+		return fmt.Errorf("NOTIFY_ON must be either \"failure\" or \"always\"")
+	}
+	if err := validateWebhooks("NOTIFY_WEBHOOKS", cfg.Notify.Webhooks); err != nil {
+		return err
+	}
+	if err := validateWebhooks("STALE_WEBHOOKS", cfg.Stale.Webhooks); err != nil {
+		return err
+	}
+	switch cfg.Attachments.Download {
+	case "":
+	case "dir", "s3":
+		if !cfg.Attachments.Import {
+			return fmt.Errorf("ATTACHMENTS_DOWNLOAD requires ATTACHMENTS_IMPORT")
+		}
+		if cfg.Attachments.Download == "s3" && cfg.Attachments.S3Bucket == "" {
+			return fmt.Errorf("ATTACHMENTS_S3_BUCKET is required when ATTACHMENTS_DOWNLOAD is \"s3\"")
+		}
+	default:
+		return fmt.Errorf("ATTACHMENTS_DOWNLOAD must be empty, \"dir\" or \"s3\"")
+	}
+	if _, err := cfg.GetCalendar(); err != nil {
+		return fmt.Errorf("invalid CALENDAR settings: %w", err)
+	}
+	return nil
+}
+
+// validateWebhooks checks the webhooks of the named setting
+func validateWebhooks(name string, webhooks []WebhookConfig) error {
+	for i, webhook := range webhooks {
+		if webhook.URL == "" {
+			return fmt.Errorf("%s[%d]: url is required", name, i)
+		}
+		switch webhook.Format {
+		case "", "json", "slack":
+		case "telegram":
+			if webhook.ChatID == "" {
+				return fmt.Errorf("%s[%d]: chat_id is required for telegram", name, i)
+			}
+		default:
+			return fmt.Errorf("%s[%d]: unsupported format %q", name, i, webhook.Format)
+		}
+	}
 	return nil
 }
 
+// GetCalendar returns the working calendar used for business time durations
+func (c *Config) GetCalendar() (*calendar.Calendar, error) {
+	return calendar.New(calendar.Config{
+		TimeZone:     c.Calendar.TimeZone,
+		WorkStart:    c.Calendar.WorkStart,
+		WorkEnd:      c.Calendar.WorkEnd,
+		Weekends:     c.Calendar.Weekends,
+		HolidaysFile: c.Calendar.HolidaysFile,
+	})
+}
+
+// GetMaxSyncAge returns the age after which the last successful sync makes the
+// service not ready. Defaults to three sync intervals.
+func (c *Config) GetMaxSyncAge() time.Duration {
+	if c.App.MaxSyncAge > 0 {
+		return c.App.MaxSyncAge
+	}
+	return 3 * c.App.SyncInterval
+}
+
 // GetDSN returns the database connection string in the format required by pgx
 func (c *Config) GetDSN() string {
 	return fmt.Sprintf("host=%s port=%d dbname=%s user=%s password=%s sslmode=%s",
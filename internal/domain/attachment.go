@@ -0,0 +1,11 @@
+package domain
+
+// AttachmentRef identifies a stored attachment whose content is not downloaded yet
+type AttachmentRef struct {
+	TrackerID  string
+	IssueKey   string
+	Name       string
+	ContentURL string
+	Mimetype   string
+	Size       int64
+}
@@ -4,13 +4,17 @@ import (
 	"context"
 	"time"
 
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/calendar"
 	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracker"
 )
 
 // IssueRepository defines the interface for issue storage operations
 type IssueRepository interface {
-	SaveIssues(ctx context.Context, issues []tracker.Issue) error
+	SaveIssues(ctx context.Context, issues []tracker.Issue) (UpsertStats, error)
 	GetLastUpdateTime(ctx context.Context, issueKey string) (*time.Time, error)
+	GetActiveIssueKeys(ctx context.Context) (map[string]string, error)
+	MarkIssuesDeleted(ctx context.Context, trackerIDs []string, at time.Time) error
+	MarkIssuesOutOfScope(ctx context.Context, trackerIDs []string, at time.Time) error
 }
 
 // ChangelogRepository defines the interface for changelog storage operations
@@ -23,9 +27,79 @@ type StatusTypeRepository interface {
 	SaveStatusTypes(ctx context.Context, statusTypes []tracker.StatusType) error
 }
 
+// UserRepository defines the interface for user storage operations
+type UserRepository interface {
+	SaveUsers(ctx context.Context, users []tracker.UserInfo) error
+}
+
+// ReferenceRepository defines the interface for reference data storage operations
+type ReferenceRepository interface {
+	SaveReferences(ctx context.Context, refs *tracker.References) error
+}
+
+// BoardRepository defines the interface for board and sprint storage operations
+type BoardRepository interface {
+	SaveBoards(ctx context.Context, boards []tracker.BoardInfo, sprints []tracker.Sprint) error
+	UpdateSprintMembership(ctx context.Context, issueKeys []string) error
+	UpdateSprintScope(ctx context.Context, cal *calendar.Calendar) error
+}
+
+// StatusHistoryRepository defines the interface for data derived from the status changelog
+type StatusHistoryRepository interface {
+	UpdateStatusIntervals(ctx context.Context, issueKeys []string, cal *calendar.Calendar) (*time.Time, error)
+	UpdateFlowMetrics(ctx context.Context, issueKeys []string, cal *calendar.Calendar) error
+	UpdateDailySnapshots(ctx context.Context, changedFrom *time.Time, cal *calendar.Calendar) error
+}
+
+// ReportRepository defines the interface for report queries
+type ReportRepository interface {
+	GetCompletionTimes(ctx context.Context, queueKey string, since time.Time) ([]time.Time, error)
+	GetStaleIssues(ctx context.Context, queueKey string, percentile float64, minSamples int) ([]StaleIssue, error)
+}
+
+// WorkflowRepository defines the interface for workflow storage operations
+type WorkflowRepository interface {
+	SaveWorkflows(ctx context.Context, workflows []tracker.Workflow) error
+	GetWorkflows(ctx context.Context, queueKey string) ([]Workflow, error)
+}
+
+// EntityRepository defines the interface for project, portfolio and goal storage operations
+type EntityRepository interface {
+	SaveEntities(ctx context.Context, entities []tracker.EntityInfo) error
+}
+
+// AttachmentRepository defines the interface for attachment storage operations
+type AttachmentRepository interface {
+	SaveAttachments(ctx context.Context, issueKeys []string, attachments []tracker.Attachment) error
+	GetPendingAttachments(ctx context.Context, issueKeys []string) ([]AttachmentRef, error)
+	SetAttachmentContent(ctx context.Context, trackerID, contentSHA256, storageKey string) error
+}
+
+// WorklogRepository defines the interface for worklog storage operations
+type WorklogRepository interface {
+	SaveWorklogs(ctx context.Context, issueKeys []string, worklogs []tracker.Worklog) error
+}
+
+// SyncRunRepository defines the interface for sync run audit storage operations
+type SyncRunRepository interface {
+	StartSyncRun(ctx context.Context, report *RunReport) error
+	FinishSyncRun(ctx context.Context, report *RunReport) error
+	GetLastSuccessfulSyncTime(ctx context.Context) (*time.Time, error)
+}
+
 // Repository combines all repository interfaces
 type Repository interface {
 	IssueRepository
 	ChangelogRepository
 	StatusTypeRepository
+	UserRepository
+	ReferenceRepository
+	BoardRepository
+	WorkflowRepository
+	StatusHistoryRepository
+	ReportRepository
+	EntityRepository
+	AttachmentRepository
+	WorklogRepository
+	SyncRunRepository
 }
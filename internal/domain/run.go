@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// Sync run statuses
+const (
+	RunStatusRunning = "running"
+	RunStatusSuccess = "success"
+	RunStatusFailed  = "failed"
+)
+
+// Sync run modes
+const (
+	RunModeFull        = "full"
+	RunModeIncremental = "incremental"
+)
+
+// RunReport describes a single synchronization run
+type RunReport struct {
+	RunID            string        `json:"run_id"`
+	OrganizationID   string        `json:"organization_id"`
+	StartedAt        time.Time     `json:"started_at"`
+	FinishedAt       time.Time     `json:"finished_at"`
+	Duration         time.Duration `json:"-"`
+	DurationSeconds  float64       `json:"duration_seconds"`
+	Mode             string        `json:"mode"`
+	Filter           string        `json:"filter"`
+	Status           string        `json:"status"`
+	IssuesFetched    int           `json:"issues_fetched"`
+	IssuesInserted   int           `json:"issues_inserted"`
+	IssuesUpdated    int           `json:"issues_updated"`
+	IssuesDeleted    int           `json:"issues_deleted"`
+	IssuesOutOfScope int           `json:"issues_out_of_scope"`
+	Changelogs       int           `json:"changelogs"`
+	Errors           int           `json:"errors"`
+	APICalls         int64         `json:"api_calls"`
+	Error            string        `json:"error,omitempty"`
+}
+
+// UpsertStats holds the number of rows inserted and updated by an upsert
+type UpsertStats struct {
+	Inserted int
+	Updated  int
+	// HistoryChangedFrom is the earliest start of the issue history versions
+	// written, nil when no tracked field changed
+	HistoryChangedFrom *time.Time
+}
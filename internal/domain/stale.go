@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// StaleIssue is an in-progress issue that has been in its current status
+// longer than the historical percentile of that status in its queue
+type StaleIssue struct {
+	Key              string    `json:"key"`
+	Summary          string    `json:"summary"`
+	QueueKey         string    `json:"queue"`
+	StatusKey        string    `json:"status_key"`
+	StatusDisplay    string    `json:"status"`
+	AssigneeDisplay  string    `json:"assignee,omitempty"`
+	StatusStartTime  time.Time `json:"status_start_time"`
+	AgeMinutes       float64   `json:"age_minutes"`
+	ThresholdMinutes float64   `json:"threshold_minutes"`
+	Samples          int       `json:"samples"`
+}
@@ -0,0 +1,25 @@
+package domain
+
+// Workflow is a stored queue workflow
+type Workflow struct {
+	QueueKey      string
+	ID            string
+	Name          string
+	InitialStatus string
+	Statuses      []WorkflowStatus
+	Transitions   []WorkflowTransition
+}
+
+// WorkflowStatus is a status of a workflow
+type WorkflowStatus struct {
+	Key     string
+	Display string
+}
+
+// WorkflowTransition is a transition between two workflow statuses
+type WorkflowTransition struct {
+	ID      string
+	Display string
+	From    string
+	To      string
+}
@@ -0,0 +1,176 @@
+// Package forecast predicts backlog completion dates from historical weekly
+// throughput with a Monte Carlo simulation
+package forecast
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"text/tabwriter"
+	"time"
+)
+
+// Supported output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// maxWeeks caps a single simulation run when the sampled throughput is mostly zero
+const maxWeeks = 520
+
+// Percentiles are the likelihoods reported by a forecast
+var Percentiles = []int{50, 70, 85, 95}
+
+// Forecast is the result of a simulation
+type Forecast struct {
+	Backlog          int          `json:"backlog"`
+	Query            string       `json:"query,omitempty"`
+	QueueKey         string       `json:"queue,omitempty"`
+	HistoryFrom      string       `json:"history_from"`
+	HistoryTo        string       `json:"history_to"`
+	WeeklyThroughput []int        `json:"weekly_throughput"`
+	Trials           int          `json:"trials"`
+	Start            string       `json:"start"`
+	Results          []Percentile `json:"percentiles"`
+}
+
+// Percentile is the completion estimate with a given likelihood
+type Percentile struct {
+	Percentile int    `json:"percentile"`
+	Weeks      int    `json:"weeks"`
+	Date       string `json:"date"`
+	Capped     bool   `json:"capped,omitempty"`
+}
+
+// WeeklyThroughput counts completions per week in the weeks starting at the
+// Monday of from and ending before the Monday of to, in the location of from.
+// The current, incomplete week is excluded.
+func WeeklyThroughput(completions []time.Time, from, to time.Time) []int {
+	start := WeekStart(from)
+	end := WeekStart(to)
+	weeks := int(end.Sub(start).Hours()/24/7 + 0.5)
+	if weeks <= 0 {
+		return nil
+	}
+
+	counts := make([]int, weeks)
+	for _, t := range completions {
+		t = t.In(start.Location())
+		if t.Before(start) || !t.Before(end) {
+			continue
+		}
+		i := int(WeekStart(t).Sub(start).Hours()/24/7 + 0.5)
+		if i >= 0 && i < weeks {
+			counts[i]++
+		}
+	}
+	return counts
+}
+
+// Simulate runs trials in which every week's throughput is sampled from the
+// history until the backlog is done, and returns the sorted number of weeks
+// each trial took
+func Simulate(throughput []int, backlog, trials int, rng *rand.Rand) ([]int, error) {
+	if backlog <= 0 {
+		return nil, fmt.Errorf("backlog must be positive")
+	}
+	if trials <= 0 {
+		return nil, fmt.Errorf("number of trials must be positive")
+	}
+	if !slices.ContainsFunc(throughput, func(n int) bool { return n > 0 }) {
+		return nil, fmt.Errorf("no issues were completed in the history window")
+	}
+
+	results := make([]int, trials)
+	for i := range results {
+		remaining := backlog
+		weeks := 0
+		for remaining > 0 && weeks < maxWeeks {
+			remaining -= throughput[rng.IntN(len(throughput))]
+			weeks++
+		}
+		results[i] = weeks
+	}
+	slices.Sort(results)
+	return results, nil
+}
+
+// PercentileResults returns the completion estimates for Percentiles from
+// the sorted simulation results, counting weeks from start
+func PercentileResults(results []int, start time.Time) []Percentile {
+	var out []Percentile
+	for _, p := range Percentiles {
+		i := (len(results)*p+99)/100 - 1
+		if i < 0 {
+			i = 0
+		}
+		weeks := results[i]
+		out = append(out, Percentile{
+			Percentile: p,
+			Weeks:      weeks,
+			Date:       start.AddDate(0, 0, 7*weeks).Format(time.DateOnly),
+			Capped:     weeks >= maxWeeks,
+		})
+	}
+	return out
+}
+
+// Render writes the forecast in the given format
+func Render(w io.Writer, format string, f Forecast) error {
+	switch format {
+	case FormatText:
+		return renderText(w, f)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(f)
+	default:
+		return fmt.Errorf("unsupported format %q (expected %q or %q)", format, FormatText, FormatJSON)
+	}
+}
+
+// renderText writes the forecast as a short summary and a table
+func renderText(w io.Writer, f Forecast) error {
+	total := 0
+	for _, n := range f.WeeklyThroughput {
+		total += n
+	}
+	average := 0.0
+	if len(f.WeeklyThroughput) > 0 {
+		average = float64(total) / float64(len(f.WeeklyThroughput))
+	}
+
+	backlog := fmt.Sprintf("%d issues", f.Backlog)
+	if f.Query != "" {
+		backlog += fmt.Sprintf(" (query: %s)", f.Query)
+	}
+	scope := "all queues"
+	if f.QueueKey != "" {
+		scope = "queue " + f.QueueKey
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Backlog:\t%s\n", backlog)
+	fmt.Fprintf(tw, "Throughput:\t%.1f issues/week in %s, %d weeks from %s to %s\n",
+		average, scope, len(f.WeeklyThroughput), f.HistoryFrom, f.HistoryTo)
+	fmt.Fprintf(tw, "Simulations:\t%d, starting %s\n", f.Trials, f.Start)
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Likelihood\tWeeks\tCompletion date")
+	for _, r := range f.Results {
+		date := r.Date
+		if r.Capped {
+			date = "not within " + fmt.Sprint(maxWeeks) + " weeks"
+		}
+		fmt.Fprintf(tw, "%d%%\t%d\t%s\n", r.Percentile, r.Weeks, date)
+	}
+	return tw.Flush()
+}
+
+// WeekStart returns the Monday midnight of the week of t in its location
+func WeekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,181 @@
+package forecast
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWeeklyThroughput(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("failed to load time zone: %v", err)
+	}
+	utc := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("failed to parse time %q: %v", value, err)
+		}
+		return parsed
+	}
+	local := func(value string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", value, berlin)
+		if err != nil {
+			t.Fatalf("failed to parse time %q: %v", value, err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name        string
+		completions []time.Time
+		from, to    time.Time
+		want        []int
+	}{
+		{
+			name: "full weeks only",
+			completions: []time.Time{
+				utc("2024-03-03T23:59:00Z"), // before the first week
+				utc("2024-03-04T00:00:00Z"),
+				utc("2024-03-10T23:59:00Z"),
+				utc("2024-03-11T12:00:00Z"),
+				utc("2024-03-24T23:00:00Z"),
+				utc("2024-03-25T00:00:00Z"), // current week
+			},
+			from: utc("2024-03-06T10:00:00Z"),
+			to:   utc("2024-03-27T10:00:00Z"),
+			want: []int{2, 1, 1},
+		},
+		{
+			name:        "weeks without completions",
+			completions: []time.Time{utc("2024-03-12T12:00:00Z")},
+			from:        utc("2024-03-04T00:00:00Z"),
+			to:          utc("2024-03-25T00:00:00Z"),
+			want:        []int{0, 1, 0},
+		},
+		{
+			name: "weeks in the location of from across a DST change",
+			completions: []time.Time{
+				utc("2024-03-17T23:30:00Z"), // Monday 00:30 in Berlin
+				utc("2024-03-31T22:30:00Z"), // Monday 00:30 in Berlin after the change
+			},
+			from: local("2024-03-20 12:00"),
+			to:   local("2024-04-10 12:00"),
+			want: []int{1, 0, 1},
+		},
+		{
+			name: "to in the same week as from",
+			from: utc("2024-03-04T00:00:00Z"),
+			to:   utc("2024-03-08T00:00:00Z"),
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeeklyThroughput(tt.completions, tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WeeklyThroughput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	tests := []struct {
+		name       string
+		throughput []int
+		backlog    int
+		trials     int
+		minWeeks   int
+		maxWeeks   int
+		wantErr    bool
+	}{
+		{name: "constant throughput", throughput: []int{5, 5, 5}, backlog: 12, trials: 100, minWeeks: 3, maxWeeks: 3},
+		{name: "varying throughput", throughput: []int{2, 0, 4}, backlog: 8, trials: 1000, minWeeks: 2, maxWeeks: maxWeeks},
+		{name: "no completions", throughput: []int{0, 0}, backlog: 8, trials: 100, wantErr: true},
+		{name: "empty backlog", throughput: []int{2}, backlog: 0, trials: 100, wantErr: true},
+		{name: "no trials", throughput: []int{2}, backlog: 8, trials: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := Simulate(tt.throughput, tt.backlog, tt.trials, rand.New(rand.NewPCG(1, 2)))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(results) != tt.trials {
+				t.Errorf("got %d results, want %d", len(results), tt.trials)
+			}
+			if !slices.IsSorted(results) {
+				t.Error("results are not sorted")
+			}
+			if results[0] < tt.minWeeks || results[len(results)-1] > tt.maxWeeks {
+				t.Errorf("results range from %d to %d weeks, want %d to %d",
+					results[0], results[len(results)-1], tt.minWeeks, tt.maxWeeks)
+			}
+		})
+	}
+}
+
+func TestPercentileResults(t *testing.T) {
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	hundred := make([]int, 100)
+	for i := range hundred {
+		hundred[i] = i + 1
+	}
+
+	tests := []struct {
+		name    string
+		results []int
+		want    []Percentile
+	}{
+		{
+			name:    "one result per percent",
+			results: hundred,
+			want: []Percentile{
+				{Percentile: 50, Weeks: 50, Date: "2025-02-17"},
+				{Percentile: 70, Weeks: 70, Date: "2025-07-07"},
+				{Percentile: 85, Weeks: 85, Date: "2025-10-20"},
+				{Percentile: 95, Weeks: 95, Date: "2025-12-29"},
+			},
+		},
+		{
+			name:    "single result",
+			results: []int{3},
+			want: []Percentile{
+				{Percentile: 50, Weeks: 3, Date: "2024-03-25"},
+				{Percentile: 70, Weeks: 3, Date: "2024-03-25"},
+				{Percentile: 85, Weeks: 3, Date: "2024-03-25"},
+				{Percentile: 95, Weeks: 3, Date: "2024-03-25"},
+			},
+		},
+		{
+			name:    "capped simulations",
+			results: []int{1, 2, 3, maxWeeks},
+			want: []Percentile{
+				{Percentile: 50, Weeks: 2, Date: "2024-03-18"},
+				{Percentile: 70, Weeks: 3, Date: "2024-03-25"},
+				{Percentile: 85, Weeks: maxWeeks, Date: start.AddDate(0, 0, 7*maxWeeks).Format(time.DateOnly), Capped: true},
+				{Percentile: 95, Weeks: maxWeeks, Date: start.AddDate(0, 0, 7*maxWeeks).Format(time.DateOnly), Capped: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PercentileResults(tt.results, start); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PercentileResults() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
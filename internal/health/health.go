@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/domain"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/database"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracker"
+)
+
+// trackerCheckTTL limits how often readiness probes call the Tracker API
+const trackerCheckTTL = time.Minute
+
+// Checker serves liveness and readiness probes
+type Checker struct {
+	db               *pgxpool.Pool
+	storage          domain.SyncRunRepository
+	tracker          *tracker.Service
+	migrationVersion uint
+	maxSyncAge       time.Duration
+
+	mu             sync.Mutex
+	trackerChecked time.Time
+	trackerErr     error
+}
+
+// NewChecker creates a new health checker. migrationVersion is the latest
+// migration the database is expected to be at; maxSyncAge of zero disables the
+// last sync check.
+func NewChecker(db *pgxpool.Pool, storage domain.SyncRunRepository, tracker *tracker.Service, migrationVersion uint, maxSyncAge time.Duration) *Checker {
+	return &Checker{
+		db:               db,
+		storage:          storage,
+		tracker:          tracker,
+		migrationVersion: migrationVersion,
+		maxSyncAge:       maxSyncAge,
+	}
+}
+
+// Healthz reports that the process is alive
+func (c *Checker) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readyz reports whether the database, migrations, Tracker token and last sync are healthy
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	checks := map[string]string{}
+	ready := true
+	record := func(name string, err error) {
+		if err != nil {
+			checks[name] = err.Error()
+			ready = false
+			return
+		}
+		checks[name] = "ok"
+	}
+
+	record("database", c.db.Ping(ctx))
+	record("migrations", c.checkMigrations(ctx))
+	record("tracker", c.checkTracker(ctx))
+	if c.maxSyncAge > 0 {
+		record("last_sync", c.checkLastSync(ctx))
+	}
+
+	status := http.StatusOK
+	response := map[string]interface{}{"status": "ok", "checks": checks}
+	if !ready {
+		status = http.StatusServiceUnavailable
+		response["status"] = "unavailable"
+	}
+	writeJSON(w, status, response)
+}
+
+// checkMigrations verifies that all migrations are applied and none is dirty
+func (c *Checker) checkMigrations(ctx context.Context) error {
+	version, dirty, err := database.CurrentMigrationVersion(ctx, c.db)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version < c.migrationVersion {
+		return fmt.Errorf("migration version %d is behind %d", version, c.migrationVersion)
+	}
+	return nil
+}
+
+// checkTracker verifies the Tracker token, caching the result for trackerCheckTTL
+func (c *Checker) checkTracker(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.trackerChecked.IsZero() && time.Since(c.trackerChecked) < trackerCheckTTL {
+		return c.trackerErr
+	}
+
+	err := c.tracker.Ping(ctx)
+	c.trackerChecked = time.Now()
+	c.trackerErr = err
+	return err
+}
+
+// checkLastSync verifies that the last successful sync is younger than maxSyncAge
+func (c *Checker) checkLastSync(ctx context.Context) error {
+	lastSync, err := c.storage.GetLastSuccessfulSyncTime(ctx)
+	if err != nil {
+		return err
+	}
+	if lastSync == nil {
+		return fmt.Errorf("no successful sync yet")
+	}
+	if age := time.Since(*lastSync); age > c.maxSyncAge {
+		return fmt.Errorf("last successful sync was %s ago", age.Round(time.Second))
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,244 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/config"
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/domain"
+)
+
+// Webhook payload formats
+const (
+	FormatJSON     = "json"
+	FormatTelegram = "telegram"
+	FormatSlack    = "slack"
+)
+
+// defaultMessage is the message text used for Telegram and Slack webhooks
+const defaultMessage = `Tracker sync {{.Status}}
+Run: {{.RunID}}
+Organization: {{.OrganizationID}}
+Mode: {{.Mode}}{{if .Filter}}, filter: {{.Filter}}{{end}}
+Duration: {{.DurationText}}
+Issues: {{.IssuesFetched}} fetched, {{.IssuesInserted}} inserted, {{.IssuesUpdated}} updated
+Changelogs: {{.Changelogs}}, API calls: {{.APICalls}}, errors: {{.Errors}}{{if .Error}}
+Error: {{.Error}}{{end}}`
+
+// Notifier posts sync run summaries to the configured webhooks
+type Notifier struct {
+	cfg        *config.Config
+	client     *http.Client
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// New creates a new notifier
+func New(cfg *config.Config) *Notifier {
+	return &Notifier{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		maxRetries: 3,
+		retryDelay: 2 * time.Second,
+	}
+}
+
+// Message is the data available to payload templates
+type Message struct {
+	domain.RunReport
+	DedupeKey    string
+	DurationText string
+}
+
+// Notify posts the run report to every webhook. Successful runs are only
+// reported when NOTIFY_ON is "always". Receivers can drop retried deliveries
+// of the same run by the Idempotency-Key header.
+func (n *Notifier) Notify(ctx context.Context, report *domain.RunReport) {
+	if len(n.cfg.Notify.Webhooks) == 0 {
+		return
+	}
+	if report.Status == domain.RunStatusSuccess && n.cfg.Notify.On != "always" {
+		return
+	}
+
+	msg := Message{
+		RunReport:    *report,
+		DedupeKey:    fmt.Sprintf("%s:%s", report.RunID, report.Status),
+		DurationText: report.Duration.Round(time.Second).String(),
+	}
+
+	for i, webhook := range n.cfg.Notify.Webhooks {
+		if err := n.send(ctx, webhook, msg); err != nil {
+			slog.Error("Failed to send notification", "webhook", i, "format", webhook.Format, "error", err)
+			continue
+		}
+		slog.Info("Sent notification", "webhook", i, "format", webhook.Format, "dedupe_key", msg.DedupeKey)
+	}
+}
+
+// Post sends a report other than a run summary to the given webhooks.
+// Telegram and Slack webhooks receive text, json webhooks receive data encoded
+// as JSON or, with a template, the template executed with data. Unlike Notify
+// it returns the first delivery error after trying every webhook.
+func (n *Notifier) Post(ctx context.Context, webhooks []config.WebhookConfig, dedupeKey, text string, data interface{}) error {
+	var firstErr error
+	for i, webhook := range webhooks {
+		payload, err := renderReportPayload(webhook, text, data)
+		if err == nil {
+			err = n.post(ctx, webhook.URL, payload, dedupeKey)
+		}
+		if err != nil {
+			slog.Error("Failed to send report", "webhook", i, "format", webhook.Format, "error", err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to send report to webhook %d: %w", i, err)
+			}
+			continue
+		}
+		slog.Info("Sent report", "webhook", i, "format", webhook.Format, "dedupe_key", dedupeKey)
+	}
+	return firstErr
+}
+
+// send renders the payload for the webhook and posts it with retries
+func (n *Notifier) send(ctx context.Context, webhook config.WebhookConfig, msg Message) error {
+	payload, err := renderPayload(webhook, msg)
+	if err != nil {
+		return err
+	}
+	return n.post(ctx, webhook.URL, payload, msg.DedupeKey)
+}
+
+// post sends the payload to the URL, retrying server errors and rate limiting
+func (n *Notifier) post(ctx context.Context, url string, payload []byte, dedupeKey string) error {
+	var lastErr error
+	for attempt := 1; attempt <= n.maxRetries; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(n.retryDelay * time.Duration(attempt-1)):
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", dedupeKey)
+
+		resp, err := n.client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to send request: %w", err)
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			break
+		}
+	}
+
+	return fmt.Errorf("max retries exceeded: %w", lastErr)
+}
+
+// renderPayload builds the request body for the webhook format. A custom
+// template replaces the whole body for json webhooks and the message text otherwise.
+func renderPayload(webhook config.WebhookConfig, msg Message) ([]byte, error) {
+	switch webhook.Format {
+	case FormatTelegram:
+		text, err := renderTemplate(webhook.Template, defaultMessage, msg)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]string{
+			"chat_id": webhook.ChatID,
+			"text":    text,
+		})
+	case FormatSlack:
+		text, err := renderTemplate(webhook.Template, defaultMessage, msg)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]string{"text": text})
+	default:
+		if webhook.Template != "" {
+			body, err := renderTemplate(webhook.Template, "", msg)
+			if err != nil {
+				return nil, err
+			}
+			return []byte(body), nil
+		}
+		return json.Marshal(struct {
+			domain.RunReport
+			DedupeKey string `json:"dedupe_key"`
+		}{msg.RunReport, msg.DedupeKey})
+	}
+}
+
+// renderReportPayload builds the request body of a report for the webhook
+// format. A custom template replaces the message text or, for json webhooks,
+// the whole body.
+func renderReportPayload(webhook config.WebhookConfig, text string, data interface{}) ([]byte, error) {
+	switch webhook.Format {
+	case FormatTelegram:
+		if webhook.Template != "" {
+			var err error
+			if text, err = renderTemplate(webhook.Template, "", data); err != nil {
+				return nil, err
+			}
+		}
+		return json.Marshal(map[string]string{
+			"chat_id": webhook.ChatID,
+			"text":    text,
+		})
+	case FormatSlack:
+		if webhook.Template != "" {
+			var err error
+			if text, err = renderTemplate(webhook.Template, "", data); err != nil {
+				return nil, err
+			}
+		}
+		return json.Marshal(map[string]string{"text": text})
+	default:
+		if webhook.Template != "" {
+			body, err := renderTemplate(webhook.Template, "", data)
+			if err != nil {
+				return nil, err
+			}
+			return []byte(body), nil
+		}
+		return json.Marshal(data)
+	}
+}
+
+// renderTemplate executes text, falling back to fallback when text is empty
+func renderTemplate(text, fallback string, data interface{}) (string, error) {
+	if text == "" {
+		text = fallback
+	}
+	tmpl, err := template.New("notification").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse notification template: %w", err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render notification template: %w", err)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/domain"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracker"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SaveAttachments upserts attachment metadata and removes the attachments of
+// the given issues that are no longer returned by Tracker
+func (s *Service) SaveAttachments(ctx context.Context, issueKeys []string, attachments []tracker.Attachment) error {
+	ctx, span := tracing.Start(ctx, "db.save_attachments", attribute.Int("db.rows", len(attachments)))
+	defer span.End()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Prepare the insert statement
+	_, err = tx.Prepare(ctx, "insert_attachment", `
+		INSERT INTO attachments (
+			organization_id, tracker_id, issue_key, name, size, mimetype,
+			created_by_id, created_by_display, created_at, content_url
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		) ON CONFLICT (organization_id, tracker_id) DO UPDATE SET
+			issue_key = EXCLUDED.issue_key,
+			name = EXCLUDED.name,
+			size = EXCLUDED.size,
+			mimetype = EXCLUDED.mimetype,
+			created_by_id = EXCLUDED.created_by_id,
+			created_by_display = EXCLUDED.created_by_display,
+			created_at = EXCLUDED.created_at,
+			content_url = EXCLUDED.content_url,
+			updated_at_db = CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert statement: %w", err)
+	}
+
+	ids := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		_, err = tx.Exec(ctx, "insert_attachment",
+			a.OrganizationID,
+			a.ID,
+			a.IssueKey,
+			a.Name,
+			a.Size,
+			a.Mimetype,
+			a.CreatedBy.ID,
+			a.CreatedBy.Display,
+			nullTime(a.CreatedAt),
+			a.Content,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert attachment %s of issue %s: %w", a.ID, a.IssueKey, err)
+		}
+		ids = append(ids, a.ID)
+	}
+
+	// Remove attachments deleted from the synced issues
+	_, err = tx.Exec(ctx, `
+		DELETE FROM attachments
+		WHERE organization_id = $3 AND issue_key = ANY($1) AND NOT (tracker_id = ANY($2))
+	`, nonNilStrings(issueKeys), nonNilStrings(ids), s.orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete removed attachments: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	metrics.RowsUpserted.WithLabelValues("attachments").Add(float64(len(attachments)))
+
+	slog.Info("Successfully saved all attachments", "total_attachments", len(attachments))
+	return nil
+}
+
+// GetPendingAttachments returns the attachments of the given issues whose content is not downloaded yet
+func (s *Service) GetPendingAttachments(ctx context.Context, issueKeys []string) ([]domain.AttachmentRef, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT tracker_id, issue_key, COALESCE(name, ''), COALESCE(content_url, ''),
+			COALESCE(mimetype, ''), COALESCE(size, 0)
+		FROM attachments
+		WHERE organization_id = $2 AND issue_key = ANY($1) AND content_sha256 IS NULL AND content_url <> ''
+		ORDER BY issue_key, tracker_id
+	`, nonNilStrings(issueKeys), s.orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []domain.AttachmentRef
+	for rows.Next() {
+		var ref domain.AttachmentRef
+		if err := rows.Scan(&ref.TrackerID, &ref.IssueKey, &ref.Name, &ref.ContentURL, &ref.Mimetype, &ref.Size); err != nil {
+			return nil, fmt.Errorf("failed to scan pending attachment: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate pending attachments: %w", err)
+	}
+	return refs, nil
+}
+
+// SetAttachmentContent records the content hash and storage key of a downloaded attachment
+func (s *Service) SetAttachmentContent(ctx context.Context, trackerID, contentSHA256, storageKey string) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE attachments
+		SET content_sha256 = $2,
+			storage_key = $3,
+			downloaded_at = CURRENT_TIMESTAMP,
+			updated_at_db = CURRENT_TIMESTAMP
+		WHERE organization_id = $4 AND tracker_id = $1
+	`, trackerID, contentSHA256, storageKey, s.orgID)
+	if err != nil {
+		return fmt.Errorf("failed to update attachment %s: %w", trackerID, err)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracker"
+)
+
+// issueChildTables lists the tables holding multi-valued issue fields
+var issueChildTables = []string{
+	"issue_tags",
+	"issue_followers",
+	"issue_components",
+	"issue_sprints",
+	"issue_boards",
+	"issue_checklist_items",
+	"issue_sla",
+}
+
+// prepareIssueChildren prepares the statements used by saveIssueChildren
+func prepareIssueChildren(ctx context.Context, tx pgx.Tx) error {
+	statements := map[string]string{
+		"insert_issue_tags": `
+			INSERT INTO issue_tags (issue_tracker_id, issue_key, tag)
+			SELECT $1, $2, t.tag
+			FROM unnest($3::text[]) AS t(tag)
+			ON CONFLICT DO NOTHING
+		`,
+		"insert_issue_followers": `
+			INSERT INTO issue_followers (issue_tracker_id, issue_key, user_id, display)
+			SELECT $1, $2, f.user_id, f.display
+			FROM unnest($3::text[], $4::text[]) AS f(user_id, display)
+			ON CONFLICT DO NOTHING
+		`,
+		"insert_issue_components": `
+			INSERT INTO issue_components (issue_tracker_id, issue_key, component_id, display)
+			SELECT $1, $2, c.component_id, c.display
+			FROM unnest($3::text[], $4::text[]) AS c(component_id, display)
+			ON CONFLICT DO NOTHING
+		`,
+		"insert_issue_sprints": `
+			INSERT INTO issue_sprints (issue_tracker_id, issue_key, sprint_id, display)
+			SELECT $1, $2, s.sprint_id, s.display
+			FROM unnest($3::text[], $4::text[]) AS s(sprint_id, display)
+			ON CONFLICT DO NOTHING
+		`,
+		"insert_issue_boards": `
+			INSERT INTO issue_boards (issue_tracker_id, issue_key, board_id, name)
+			SELECT $1, $2, b.board_id, b.name
+			FROM unnest($3::int[], $4::text[]) AS b(board_id, name)
+			ON CONFLICT DO NOTHING
+		`,
+		"insert_issue_checklist_items": `
+			INSERT INTO issue_checklist_items (
+				issue_tracker_id, issue_key, item_id, position, text, checked, assignee_id,
+				assignee_display, deadline, deadline_type, deadline_exceeded, item_type
+			)
+			SELECT $1, $2, c.item_id, c.position, c.text, c.checked, NULLIF(c.assignee_id, ''),
+				NULLIF(c.assignee_display, ''), c.deadline, NULLIF(c.deadline_type, ''),
+				c.deadline_exceeded, NULLIF(c.item_type, '')
+			FROM unnest(
+				$3::text[], $4::int[], $5::text[], $6::bool[], $7::text[], $8::text[],
+				$9::timestamptz[], $10::text[], $11::bool[], $12::text[]
+			) AS c(
+				item_id, position, text, checked, assignee_id, assignee_display,
+				deadline, deadline_type, deadline_exceeded, item_type
+			)
+			ON CONFLICT DO NOTHING
+		`,
+		"insert_issue_sla": `
+			INSERT INTO issue_sla (
+				issue_tracker_id, issue_key, sla_id, settings_id, clock_status, violation_status,
+				warn_threshold, fail_threshold, started_at, paused_at, paused_duration, warn_at,
+				deadline, failed_at, spent
+			)
+			SELECT $1, $2, t.sla_id, NULLIF(t.settings_id, ''), NULLIF(t.clock_status, ''),
+				NULLIF(t.violation_status, ''), t.warn_threshold, t.fail_threshold, t.started_at,
+				t.paused_at, t.paused_duration, t.warn_at, t.deadline, t.failed_at, t.spent
+			FROM unnest(
+				$3::text[], $4::text[], $5::text[], $6::text[], $7::bigint[], $8::bigint[],
+				$9::timestamptz[], $10::timestamptz[], $11::bigint[], $12::timestamptz[],
+				$13::timestamptz[], $14::timestamptz[], $15::bigint[]
+			) AS t(
+				sla_id, settings_id, clock_status, violation_status, warn_threshold, fail_threshold,
+				started_at, paused_at, paused_duration, warn_at, deadline, failed_at, spent
+			)
+			ON CONFLICT DO NOTHING
+		`,
+	}
+	for _, table := range issueChildTables {
+		statements["delete_"+table] = fmt.Sprintf(`DELETE FROM %s WHERE issue_tracker_id = $1`, table)
+	}
+
+	for name, sql := range statements {
+		if _, err := tx.Prepare(ctx, name, sql); err != nil {
+			return fmt.Errorf("failed to prepare %s statement: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// saveIssueChildren replaces the multi-valued field rows of the issue
+func saveIssueChildren(ctx context.Context, tx pgx.Tx, issue tracker.Issue) error {
+	for _, table := range issueChildTables {
+		if _, err := tx.Exec(ctx, "delete_"+table, issue.ID); err != nil {
+			return fmt.Errorf("failed to clear %s of issue %s: %w", table, issue.Key, err)
+		}
+	}
+
+	followerIDs, followerDisplays := splitUsers(issue.Followers)
+	componentIDs, componentDisplays := splitEntities(issue.Components)
+	sprintIDs, sprintDisplays := splitEntities(issue.Sprint)
+	boardIDs := make([]int32, 0, len(issue.Boards))
+	boardNames := make([]string, 0, len(issue.Boards))
+	for _, b := range issue.Boards {
+		boardIDs = append(boardIDs, int32(b.ID))
+		boardNames = append(boardNames, b.Name)
+	}
+
+	inserts := []struct {
+		statement string
+		args      []interface{}
+	}{
+		{"insert_issue_tags", []interface{}{issue.ID, issue.Key, nonNilStrings(issue.Tags)}},
+		{"insert_issue_followers", []interface{}{issue.ID, issue.Key, followerIDs, followerDisplays}},
+		{"insert_issue_components", []interface{}{issue.ID, issue.Key, componentIDs, componentDisplays}},
+		{"insert_issue_sprints", []interface{}{issue.ID, issue.Key, sprintIDs, sprintDisplays}},
+		{"insert_issue_boards", []interface{}{issue.ID, issue.Key, boardIDs, boardNames}},
+		{"insert_issue_checklist_items", checklistArgs(issue)},
+		{"insert_issue_sla", slaArgs(issue)},
+	}
+	for _, insert := range inserts {
+		if _, err := tx.Exec(ctx, insert.statement, insert.args...); err != nil {
+			return fmt.Errorf("failed to %s for issue %s: %w", insert.statement, issue.Key, err)
+		}
+	}
+	return nil
+}
+
+// checklistArgs returns the arguments of insert_issue_checklist_items, one
+// array per column with the items in checklist order
+func checklistArgs(issue tracker.Issue) []interface{} {
+	n := len(issue.ChecklistItems)
+	ids := make([]string, 0, n)
+	positions := make([]int32, 0, n)
+	texts := make([]string, 0, n)
+	checked := make([]bool, 0, n)
+	assigneeIDs := make([]string, 0, n)
+	assigneeDisplays := make([]string, 0, n)
+	deadlines := make([]*time.Time, 0, n)
+	deadlineTypes := make([]string, 0, n)
+	exceeded := make([]bool, 0, n)
+	types := make([]string, 0, n)
+	for i, item := range issue.ChecklistItems {
+		if item.ID == "" {
+			continue
+		}
+		var deadline *time.Time
+		if t := item.Deadline.Date.Time(); !t.IsZero() {
+			deadline = &t
+		}
+		ids = append(ids, item.ID)
+		positions = append(positions, int32(i))
+		texts = append(texts, item.Text)
+		checked = append(checked, item.Checked)
+		assigneeIDs = append(assigneeIDs, item.Assignee.ID.String())
+		assigneeDisplays = append(assigneeDisplays, item.Assignee.Display)
+		deadlines = append(deadlines, deadline)
+		deadlineTypes = append(deadlineTypes, item.Deadline.DeadlineType)
+		exceeded = append(exceeded, item.Deadline.IsExceeded)
+		types = append(types, item.ChecklistItemType)
+	}
+	return []interface{}{
+		issue.ID, issue.Key, ids, positions, texts, checked, assigneeIDs, assigneeDisplays,
+		deadlines, deadlineTypes, exceeded, types,
+	}
+}
+
+// slaArgs returns the arguments of insert_issue_sla, one array per column.
+// A failed timer without a failure time is counted as failed at its deadline.
+func slaArgs(issue tracker.Issue) []interface{} {
+	n := len(issue.SLA)
+	ids := make([]string, 0, n)
+	settingsIDs := make([]string, 0, n)
+	clockStatuses := make([]string, 0, n)
+	violationStatuses := make([]string, 0, n)
+	warnThresholds := make([]int64, 0, n)
+	failThresholds := make([]int64, 0, n)
+	startedAt := make([]*time.Time, 0, n)
+	pausedAt := make([]*time.Time, 0, n)
+	pausedDurations := make([]int64, 0, n)
+	warnAt := make([]*time.Time, 0, n)
+	deadlines := make([]*time.Time, 0, n)
+	failedAt := make([]*time.Time, 0, n)
+	spent := make([]int64, 0, n)
+	for _, t := range issue.SLA {
+		if t.ID == "" {
+			continue
+		}
+		failed := t.FailedAt
+		if t.Failed() && failed.Time().IsZero() {
+			failed = t.Deadline()
+		}
+		ids = append(ids, t.ID)
+		settingsIDs = append(settingsIDs, t.SettingsID)
+		clockStatuses = append(clockStatuses, t.ClockStatus)
+		violationStatuses = append(violationStatuses, t.ViolationStatus)
+		warnThresholds = append(warnThresholds, t.WarnThreshold)
+		failThresholds = append(failThresholds, t.FailThreshold)
+		startedAt = append(startedAt, timePtr(t.StartedAt))
+		pausedAt = append(pausedAt, timePtr(t.PausedAt))
+		pausedDurations = append(pausedDurations, t.PausedDuration)
+		warnAt = append(warnAt, timePtr(t.WarnAt))
+		deadlines = append(deadlines, timePtr(t.Deadline()))
+		failedAt = append(failedAt, timePtr(failed))
+		spent = append(spent, t.Spent)
+	}
+	return []interface{}{
+		issue.ID, issue.Key, ids, settingsIDs, clockStatuses, violationStatuses, warnThresholds,
+		failThresholds, startedAt, pausedAt, pausedDurations, warnAt, deadlines, failedAt, spent,
+	}
+}
+
+// slaSummary joins the SLA timers of an issue as "settings ID: violation status"
+// for the legacy sla column
+func slaSummary(timers []tracker.SLATimer) string {
+	parts := make([]string, 0, len(timers))
+	for _, t := range timers {
+		parts = append(parts, t.SettingsID+": "+t.ViolationStatus)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// timePtr returns nil for a zero time so that it is stored as NULL
+func timePtr(t tracker.Time) *time.Time {
+	if t.Time().IsZero() {
+		return nil
+	}
+	v := t.Time()
+	return &v
+}
+
+// splitEntities returns entity IDs and displays as parallel slices, skipping entities without ID
+func splitEntities(entities []tracker.Entity) ([]string, []string) {
+	ids := make([]string, 0, len(entities))
+	displays := make([]string, 0, len(entities))
+	for _, e := range entities {
+		if e.ID == "" {
+			continue
+		}
+		ids = append(ids, e.ID)
+		displays = append(displays, e.Display)
+	}
+	return ids, displays
+}
+
+// splitUsers returns user IDs and displays as parallel slices, skipping users without ID
+func splitUsers(users []tracker.User) ([]string, []string) {
+	ids := make([]string, 0, len(users))
+	displays := make([]string, 0, len(users))
+	for _, u := range users {
+		if u.ID == "" {
+			continue
+		}
+		ids = append(ids, u.ID)
+		displays = append(displays, u.Display)
+	}
+	return ids, displays
+}
+
+// nonNilStrings returns an empty slice instead of nil so it is sent as an empty array
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
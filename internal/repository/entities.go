@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracker"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SaveEntities upserts projects, portfolios and goals, replaces their links
+// and status history and removes entities that no longer exist in Tracker
+func (s *Service) SaveEntities(ctx context.Context, entities []tracker.EntityInfo) error {
+	ctx, span := tracing.Start(ctx, "db.save_entities", attribute.Int("db.rows", len(entities)))
+	defer span.End()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Clear the links and status history of the organization's entities
+	for _, table := range []string{"entity_links", "entity_status_history"} {
+		_, err := tx.Exec(ctx, fmt.Sprintf(`
+			DELETE FROM %s WHERE organization_id = $1
+		`, table), s.orgID)
+		if err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+
+	ids := make([]string, 0, len(entities))
+	links, changes := 0, 0
+	for _, e := range entities {
+		var fields interface{}
+		if len(e.Fields.Raw) > 0 {
+			fields = string(e.Fields.Raw)
+		}
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO entities (
+				organization_id, tracker_id, short_id, entity_type, summary, description,
+				entity_status, lead_id, lead_display, start_date, end_date, end_date_type,
+				parent_id, parent_type, tags, fields, created_by_id, created_by_display,
+				created_at, updated_at
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+			) ON CONFLICT (organization_id, tracker_id) DO UPDATE SET
+				short_id = EXCLUDED.short_id,
+				entity_type = EXCLUDED.entity_type,
+				summary = EXCLUDED.summary,
+				description = EXCLUDED.description,
+				entity_status = EXCLUDED.entity_status,
+				lead_id = EXCLUDED.lead_id,
+				lead_display = EXCLUDED.lead_display,
+				start_date = EXCLUDED.start_date,
+				end_date = EXCLUDED.end_date,
+				end_date_type = EXCLUDED.end_date_type,
+				parent_id = EXCLUDED.parent_id,
+				parent_type = EXCLUDED.parent_type,
+				tags = EXCLUDED.tags,
+				fields = EXCLUDED.fields,
+				created_by_id = EXCLUDED.created_by_id,
+				created_by_display = EXCLUDED.created_by_display,
+				created_at = EXCLUDED.created_at,
+				updated_at = EXCLUDED.updated_at,
+				updated_at_db = CURRENT_TIMESTAMP
+		`,
+			e.OrganizationID, e.ID, e.ShortID, e.EntityType, e.Fields.Summary, e.Fields.Description,
+			nullString(e.Fields.EntityStatus), e.Fields.Lead.ID, e.Fields.Lead.Display,
+			nullTime(e.Fields.Start.Date), nullTime(e.Fields.End.Date), nullString(e.Fields.End.DateType),
+			nullString(e.Fields.ParentEntity.ID), nullString(e.Fields.ParentEntity.EntityType),
+			strings.Join(e.Fields.Tags, ", "), fields, e.CreatedBy.ID, e.CreatedBy.Display,
+			nullTime(e.CreatedAt), nullTime(e.UpdatedAt),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert %s %s: %w", e.EntityType, e.ID, err)
+		}
+		ids = append(ids, e.ID)
+
+		for _, l := range e.Links {
+			linked := l.LinkedEntity
+			tag, err := tx.Exec(ctx, `
+				INSERT INTO entity_links (
+					organization_id, entity_id, link_type, direction, linked_id, linked_type, linked_key,
+					linked_display
+				) VALUES (
+					$1, $2, $3, $4, $5, $6, $7, $8
+				) ON CONFLICT DO NOTHING
+			`, e.OrganizationID, e.ID, l.Type.ID, l.Direction, linked.ID, nullString(linked.EntityType),
+				nullString(linked.Key), linked.Display)
+			if err != nil {
+				return fmt.Errorf("failed to insert link of %s %s: %w", e.EntityType, e.ID, err)
+			}
+			links += int(tag.RowsAffected())
+		}
+
+		for _, c := range e.StatusHistory {
+			tag, err := tx.Exec(ctx, `
+				INSERT INTO entity_status_history (
+					organization_id, entity_id, event_id, changed_at, author_id, author_display, from_status,
+					to_status
+				) VALUES (
+					$1, $2, $3, $4, $5, $6, $7, $8
+				) ON CONFLICT DO NOTHING
+			`, e.OrganizationID, e.ID, c.EventID, nullTime(c.ChangedAt), c.Author.ID, c.Author.Display,
+				nullString(c.From), nullString(c.To))
+			if err != nil {
+				return fmt.Errorf("failed to insert status change of %s %s: %w", e.EntityType, e.ID, err)
+			}
+			changes += int(tag.RowsAffected())
+		}
+	}
+
+	// Remove entities that no longer exist in Tracker
+	if err := deleteMissing(ctx, tx, "entities", s.orgID, ids); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	metrics.RowsUpserted.WithLabelValues("entities").Add(float64(len(entities)))
+	metrics.RowsUpserted.WithLabelValues("entity_links").Add(float64(links))
+	metrics.RowsUpserted.WithLabelValues("entity_status_history").Add(float64(changes))
+
+	slog.Info("Successfully saved all entities",
+		"total_entities", len(entities),
+		"links", links,
+		"status_changes", changes)
+	return nil
+}
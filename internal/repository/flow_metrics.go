@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/calendar"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Status types of Tracker statuses used as flow categories
+const (
+	statusTypeNew        = "new"
+	statusTypeInProgress = "inProgress"
+	statusTypePaused     = "paused"
+	statusTypeDone       = "done"
+	statusTypeCancelled  = "cancelled"
+)
+
+// flowMetrics are the lead time, cycle time and time per status category of an issue
+type flowMetrics struct {
+	issueKey          string
+	createdAt         time.Time
+	firstInProgressAt *time.Time
+	firstDoneAt       *time.Time
+	cycleEndAt        *time.Time
+	categoryMinutes   map[string]float64
+	categoryBusiness  map[string]float64
+	reopenCount       int
+}
+
+// leadTime returns the minutes from creation to the first done status
+func (m flowMetrics) leadTime() *float64 {
+	if m.firstDoneAt == nil {
+		return nil
+	}
+	minutes := m.firstDoneAt.Sub(m.createdAt).Minutes()
+	return &minutes
+}
+
+// leadTimeBusiness returns the working minutes from creation to the first done status
+func (m flowMetrics) leadTimeBusiness(cal *calendar.Calendar) *float64 {
+	if m.firstDoneAt == nil {
+		return nil
+	}
+	minutes := cal.WorkingMinutes(m.createdAt, *m.firstDoneAt)
+	return &minutes
+}
+
+// cycleTime returns the minutes from the first in progress status to the first done status after it
+func (m flowMetrics) cycleTime() *float64 {
+	if m.firstInProgressAt == nil || m.cycleEndAt == nil {
+		return nil
+	}
+	minutes := m.cycleEndAt.Sub(*m.firstInProgressAt).Minutes()
+	return &minutes
+}
+
+// cycleTimeBusiness returns the working minutes of the cycle time
+func (m flowMetrics) cycleTimeBusiness(cal *calendar.Calendar) *float64 {
+	if m.firstInProgressAt == nil || m.cycleEndAt == nil {
+		return nil
+	}
+	minutes := cal.WorkingMinutes(*m.firstInProgressAt, *m.cycleEndAt)
+	return &minutes
+}
+
+// UpdateFlowMetrics recomputes the issue_flow_metrics rows of the given issues
+// from their status history, using the status type as the flow category.
+// Business minutes are counted in the working time of the calendar.
+func (s *Service) UpdateFlowMetrics(ctx context.Context, issueKeys []string, cal *calendar.Calendar) error {
+	ctx, span := tracing.Start(ctx, "db.update_flow_metrics", attribute.Int("db.issues", len(issueKeys)))
+	defer span.End()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	keys := nonNilStrings(issueKeys)
+
+	issues, changes, statusTypes, err := loadStatusHistory(ctx, tx, s.orgID, keys)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM issue_flow_metrics WHERE organization_id = $2 AND issue_key = ANY($1)`, keys, s.orgID)
+	if err != nil {
+		return fmt.Errorf("failed to clear flow metrics: %w", err)
+	}
+
+	now := time.Now().UTC()
+	for key, issue := range issues {
+		intervals := buildStatusIntervals(key, issue, changes[key], statusTypes)
+		m := buildFlowMetrics(key, issue.createdAt, intervals, now, cal)
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO issue_flow_metrics (
+				issue_key, created_at, first_in_progress_at, first_done_at, lead_time_minutes,
+				cycle_time_minutes, new_minutes, in_progress_minutes, paused_minutes, done_minutes,
+				cancelled_minutes, reopen_count, computed_at, lead_time_business_minutes,
+				cycle_time_business_minutes, new_business_minutes, in_progress_business_minutes,
+				paused_business_minutes, done_business_minutes, cancelled_business_minutes, organization_id
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
+			)
+		`, m.issueKey, m.createdAt, m.firstInProgressAt, m.firstDoneAt, m.leadTime(),
+			m.cycleTime(), m.categoryMinutes[statusTypeNew], m.categoryMinutes[statusTypeInProgress],
+			m.categoryMinutes[statusTypePaused], m.categoryMinutes[statusTypeDone],
+			m.categoryMinutes[statusTypeCancelled], m.reopenCount, now, m.leadTimeBusiness(cal),
+			m.cycleTimeBusiness(cal), m.categoryBusiness[statusTypeNew], m.categoryBusiness[statusTypeInProgress],
+			m.categoryBusiness[statusTypePaused], m.categoryBusiness[statusTypeDone],
+			m.categoryBusiness[statusTypeCancelled], s.orgID)
+		if err != nil {
+			return fmt.Errorf("failed to insert flow metrics of issue %s: %w", key, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	metrics.RowsUpserted.WithLabelValues("issue_flow_metrics").Add(float64(len(issues)))
+
+	slog.Info("Successfully updated flow metrics", "issues", len(issues))
+	return nil
+}
+
+// buildFlowMetrics computes the flow metrics of an issue from its status
+// intervals. The current status is counted up to now unless it is done or
+// cancelled, so the time of closed issues does not keep growing. An issue is
+// reopened when it leaves a done or cancelled status for any other status type.
+func buildFlowMetrics(issueKey string, createdAt time.Time, intervals []statusInterval, now time.Time, cal *calendar.Calendar) flowMetrics {
+	m := flowMetrics{
+		issueKey:         issueKey,
+		createdAt:        createdAt,
+		categoryMinutes:  make(map[string]float64),
+		categoryBusiness: make(map[string]float64),
+	}
+
+	previousType := ""
+	for _, interval := range intervals {
+		exited := now
+		if interval.exitedAt != nil {
+			exited = *interval.exitedAt
+		}
+		stillClosed := interval.exitedAt == nil && isClosedStatusType(interval.statusType)
+		if interval.statusType != "" && !stillClosed {
+			m.categoryMinutes[interval.statusType] += exited.Sub(interval.enteredAt).Minutes()
+			m.categoryBusiness[interval.statusType] += cal.WorkingMinutes(interval.enteredAt, exited)
+		}
+
+		entered := interval.enteredAt
+		switch interval.statusType {
+		case statusTypeInProgress:
+			if m.firstInProgressAt == nil {
+				m.firstInProgressAt = &entered
+			}
+		case statusTypeDone:
+			if m.firstDoneAt == nil {
+				m.firstDoneAt = &entered
+			}
+			if m.firstInProgressAt != nil && m.cycleEndAt == nil {
+				m.cycleEndAt = &entered
+			}
+		}
+
+		if isClosedStatusType(previousType) && interval.statusType != "" && !isClosedStatusType(interval.statusType) {
+			m.reopenCount++
+		}
+		previousType = interval.statusType
+	}
+	return m
+}
+
+// isClosedStatusType reports whether the status type ends the work on an issue
+func isClosedStatusType(statusType string) bool {
+	return statusType == statusTypeDone || statusType == statusTypeCancelled
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/calendar"
+)
+
+func minutes(value float64) *float64 {
+	return &value
+}
+
+func sameMinutes(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func TestBuildFlowMetrics(t *testing.T) {
+	cal, err := calendar.New(calendar.Config{
+		TimeZone:  "UTC",
+		WorkStart: "09:00",
+		WorkEnd:   "18:00",
+		Weekends:  []string{"saturday", "sunday"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create calendar: %v", err)
+	}
+
+	// 2024-03-04 is a Monday
+	created := at(4, 10)
+	interval := func(statusType string, entered time.Time, exited *time.Time) statusInterval {
+		return statusInterval{issueKey: "TEST-1", statusType: statusType, enteredAt: entered, exitedAt: exited}
+	}
+
+	tests := []struct {
+		name          string
+		intervals     []statusInterval
+		now           time.Time
+		wantLead      *float64
+		wantLeadBiz   *float64
+		wantCycle     *float64
+		wantCycleBiz  *float64
+		wantMinutes   map[string]float64
+		wantBusiness  map[string]float64
+		wantReopenCnt int
+	}{
+		{
+			name: "done issue stops counting at done",
+			intervals: []statusInterval{
+				interval(statusTypeNew, at(4, 10), atPtr(4, 12)),
+				interval(statusTypeInProgress, at(4, 12), atPtr(5, 12)),
+				interval(statusTypeDone, at(5, 12), nil),
+			},
+			now:          at(8, 10),
+			wantLead:     minutes(26 * 60),
+			wantLeadBiz:  minutes(11 * 60),
+			wantCycle:    minutes(24 * 60),
+			wantCycleBiz: minutes(9 * 60),
+			wantMinutes:  map[string]float64{statusTypeNew: 120, statusTypeInProgress: 24 * 60},
+			wantBusiness: map[string]float64{statusTypeNew: 120, statusTypeInProgress: 9 * 60},
+		},
+		{
+			name: "issue in progress counts up to now",
+			intervals: []statusInterval{
+				interval(statusTypeNew, at(4, 10), atPtr(4, 12)),
+				interval(statusTypeInProgress, at(4, 12), nil),
+			},
+			now:          at(5, 12),
+			wantMinutes:  map[string]float64{statusTypeNew: 120, statusTypeInProgress: 24 * 60},
+			wantBusiness: map[string]float64{statusTypeNew: 120, statusTypeInProgress: 9 * 60},
+		},
+		{
+			name: "reopened issue",
+			intervals: []statusInterval{
+				interval(statusTypeNew, at(4, 10), atPtr(4, 12)),
+				interval(statusTypeDone, at(4, 12), atPtr(5, 12)),
+				interval(statusTypeInProgress, at(5, 12), atPtr(6, 12)),
+				interval(statusTypeDone, at(6, 12), nil),
+			},
+			now:          at(8, 10),
+			wantLead:     minutes(120),
+			wantLeadBiz:  minutes(120),
+			wantCycle:    minutes(24 * 60),
+			wantCycleBiz: minutes(9 * 60),
+			wantMinutes: map[string]float64{
+				statusTypeNew: 120, statusTypeDone: 24 * 60, statusTypeInProgress: 24 * 60,
+			},
+			wantBusiness: map[string]float64{
+				statusTypeNew: 120, statusTypeDone: 9 * 60, statusTypeInProgress: 9 * 60,
+			},
+			wantReopenCnt: 1,
+		},
+		{
+			name: "cancelled issue has no lead time",
+			intervals: []statusInterval{
+				interval(statusTypeNew, at(4, 10), atPtr(4, 12)),
+				interval(statusTypeCancelled, at(4, 12), nil),
+			},
+			now:          at(8, 10),
+			wantMinutes:  map[string]float64{statusTypeNew: 120},
+			wantBusiness: map[string]float64{statusTypeNew: 120},
+		},
+		{
+			name: "weekend is not business time",
+			intervals: []statusInterval{
+				interval(statusTypeInProgress, at(1, 17), atPtr(4, 10)),
+				interval(statusTypeDone, at(4, 10), nil),
+			},
+			now:          at(8, 10),
+			wantLead:     minutes(0),
+			wantLeadBiz:  minutes(0),
+			wantCycle:    minutes(65 * 60),
+			wantCycleBiz: minutes(2 * 60),
+			wantMinutes:  map[string]float64{statusTypeInProgress: 65 * 60},
+			wantBusiness: map[string]float64{statusTypeInProgress: 2 * 60},
+		},
+		{
+			name: "statuses without a type are not counted",
+			intervals: []statusInterval{
+				interval("", at(4, 10), atPtr(4, 12)),
+				interval(statusTypeInProgress, at(4, 12), atPtr(4, 14)),
+			},
+			now:          at(8, 10),
+			wantMinutes:  map[string]float64{statusTypeInProgress: 120},
+			wantBusiness: map[string]float64{statusTypeInProgress: 120},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := buildFlowMetrics("TEST-1", created, tt.intervals, tt.now, cal)
+
+			if got := m.leadTime(); !sameMinutes(got, tt.wantLead) {
+				t.Errorf("leadTime() = %v, want %v", deref(got), deref(tt.wantLead))
+			}
+			if got := m.leadTimeBusiness(cal); !sameMinutes(got, tt.wantLeadBiz) {
+				t.Errorf("leadTimeBusiness() = %v, want %v", deref(got), deref(tt.wantLeadBiz))
+			}
+			if got := m.cycleTime(); !sameMinutes(got, tt.wantCycle) {
+				t.Errorf("cycleTime() = %v, want %v", deref(got), deref(tt.wantCycle))
+			}
+			if got := m.cycleTimeBusiness(cal); !sameMinutes(got, tt.wantCycleBiz) {
+				t.Errorf("cycleTimeBusiness() = %v, want %v", deref(got), deref(tt.wantCycleBiz))
+			}
+			if !reflect.DeepEqual(m.categoryMinutes, tt.wantMinutes) {
+				t.Errorf("categoryMinutes = %v, want %v", m.categoryMinutes, tt.wantMinutes)
+			}
+			if !reflect.DeepEqual(m.categoryBusiness, tt.wantBusiness) {
+				t.Errorf("categoryBusiness = %v, want %v", m.categoryBusiness, tt.wantBusiness)
+			}
+			if m.reopenCount != tt.wantReopenCnt {
+				t.Errorf("reopenCount = %d, want %d", m.reopenCount, tt.wantReopenCnt)
+			}
+		})
+	}
+}
+
+func deref(value *float64) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracker"
+)
+
+// issueSnapshot holds the issue fields tracked in issue_history. A new history
+// version is written whenever any of them changes.
+type issueSnapshot struct {
+	Summary           string
+	QueueKey          string
+	TypeDisplay       string
+	PriorityDisplay   string
+	StatusKey         string
+	StatusDisplay     string
+	StatusType        string
+	ResolutionDisplay string
+	AssigneeDisplay   string
+	ProjectDisplay    string
+	ParentKey         string
+	EpicDisplay       string
+	SprintDisplay     string
+	ComponentsDisplay string
+	Tags              string
+	StoryPoints       float64
+	Deadline          time.Time
+}
+
+func newIssueSnapshot(issue tracker.Issue) issueSnapshot {
+	return issueSnapshot{
+		Summary:           issue.Summary,
+		QueueKey:          issue.Queue.Key,
+		TypeDisplay:       issue.Type.Display,
+		PriorityDisplay:   issue.Priority.Display,
+		StatusKey:         issue.Status.Key,
+		StatusDisplay:     issue.Status.Display,
+		StatusType:        issue.StatusType.Display,
+		ResolutionDisplay: issue.Resolution.Display,
+		AssigneeDisplay:   issue.Assignee.Display,
+		ProjectDisplay:    issue.Project.Display,
+		ParentKey:         issue.Parent.Key,
+		EpicDisplay:       issue.Epic.Display,
+		SprintDisplay:     strings.Join(getEntityDisplays(issue.Sprint), ", "),
+		ComponentsDisplay: strings.Join(getEntityDisplays(issue.Components), ", "),
+		Tags:              strings.Join(issue.Tags, ", "),
+		StoryPoints:       issue.StoryPoints,
+		Deadline:          issue.Deadline.Time(),
+	}
+}
+
+// hash returns a stable hash of the snapshot values
+func (s issueSnapshot) hash() (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal issue snapshot: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// prepareIssueHistory prepares the statements used by saveIssueHistory
+func prepareIssueHistory(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Prepare(ctx, "close_issue_history", `
+		UPDATE issue_history SET
+			valid_to = GREATEST($2, valid_from)
+		WHERE tracker_id = $1
+			AND valid_to IS NULL
+			AND row_hash <> $3
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare close history statement: %w", err)
+	}
+
+	_, err = tx.Prepare(ctx, "insert_issue_history", `
+		INSERT INTO issue_history (
+			organization_id, tracker_id, key, valid_from, row_hash,
+			summary, queue_key, type_display, priority_display, status_key,
+			status_display, status_type, resolution_display, assignee_display,
+			project_display, parent_key, epic_display, sprint_display,
+			components_display, tags, story_points, deadline
+		)
+		SELECT
+			$1, $2, $3,
+			GREATEST($4::timestamptz, COALESCE(
+				(SELECT MAX(valid_to) FROM issue_history WHERE tracker_id = $2),
+				$4::timestamptz
+			)),
+			$5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+			$15, $16, $17, $18, $19, $20, $21::numeric, $22::timestamptz
+		WHERE NOT EXISTS (
+			SELECT 1
+			FROM issue_history
+			WHERE tracker_id = $2
+				AND valid_to IS NULL
+		)
+		RETURNING valid_from
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert history statement: %w", err)
+	}
+
+	return nil
+}
+
+// saveIssueHistory closes the open history version of the issue if tracked
+// fields changed and opens a new one valid from the issue's last update. It
+// returns the start of the new version, or nil when nothing changed.
+func saveIssueHistory(ctx context.Context, tx pgx.Tx, issue tracker.Issue) (*time.Time, error) {
+	snapshot := newIssueSnapshot(issue)
+	hash, err := snapshot.hash()
+	if err != nil {
+		return nil, err
+	}
+
+	validFrom := issue.UpdatedAt.Time()
+	if validFrom.IsZero() {
+		validFrom = time.Now().UTC()
+	}
+
+	if _, err := tx.Exec(ctx, "close_issue_history", issue.ID, validFrom, hash); err != nil {
+		return nil, fmt.Errorf("failed to close history of issue %s: %w", issue.Key, err)
+	}
+
+	var versionFrom time.Time
+	err = tx.QueryRow(ctx, "insert_issue_history",
+		issue.OrganizationID,
+		issue.ID,
+		issue.Key,
+		validFrom,
+		hash,
+		snapshot.Summary,
+		snapshot.QueueKey,
+		snapshot.TypeDisplay,
+		snapshot.PriorityDisplay,
+		snapshot.StatusKey,
+		snapshot.StatusDisplay,
+		snapshot.StatusType,
+		snapshot.ResolutionDisplay,
+		snapshot.AssigneeDisplay,
+		snapshot.ProjectDisplay,
+		snapshot.ParentKey,
+		snapshot.EpicDisplay,
+		snapshot.SprintDisplay,
+		snapshot.ComponentsDisplay,
+		snapshot.Tags,
+		func() interface{} {
+			if snapshot.StoryPoints == 0 {
+				return nil
+			}
+			return snapshot.StoryPoints
+		}(),
+		func() interface{} {
+			if snapshot.Deadline.IsZero() {
+				return nil
+			}
+			return snapshot.Deadline
+		}(),
+	).Scan(&versionFrom)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert history of issue %s: %w", issue.Key, err)
+	}
+
+	return &versionFrom, nil
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracker"
+)
+
+// SaveReferences replaces the queues, projects, components, versions, issue
+// types, priorities and resolutions of the organization with the given
+// reference data in a single transaction
+func (s *Service) SaveReferences(ctx context.Context, refs *tracker.References) error {
+	ctx, span := tracing.Start(ctx, "db.save_references")
+	defer span.End()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var queueIDs []string
+	for _, q := range refs.Queues {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO queues (
+				organization_id, tracker_id, key, name, description, lead_id, lead_display,
+				assign_auto, default_type_key, default_type_display, default_priority_key,
+				default_priority_display, deny_voting
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+			) ON CONFLICT (organization_id, tracker_id) DO UPDATE SET
+				key = EXCLUDED.key,
+				name = EXCLUDED.name,
+				description = EXCLUDED.description,
+				lead_id = EXCLUDED.lead_id,
+				lead_display = EXCLUDED.lead_display,
+				assign_auto = EXCLUDED.assign_auto,
+				default_type_key = EXCLUDED.default_type_key,
+				default_type_display = EXCLUDED.default_type_display,
+				default_priority_key = EXCLUDED.default_priority_key,
+				default_priority_display = EXCLUDED.default_priority_display,
+				deny_voting = EXCLUDED.deny_voting,
+				updated_at_db = CURRENT_TIMESTAMP
+		`,
+			q.OrganizationID, q.TrackerID, q.Key, q.Name, q.Description, q.Lead.ID, q.Lead.Display,
+			q.AssignAuto, q.DefaultType.Key, q.DefaultType.Display, q.DefaultPriority.Key,
+			q.DefaultPriority.Display, q.DenyVoting,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert queue %s: %w", q.Key, err)
+		}
+		queueIDs = append(queueIDs, q.TrackerID)
+	}
+
+	var projectIDs []string
+	for _, p := range refs.Projects {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO projects (
+				organization_id, tracker_id, key, name, description, lead_id, lead_display,
+				status, start_date, end_date
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			) ON CONFLICT (organization_id, tracker_id) DO UPDATE SET
+				key = EXCLUDED.key,
+				name = EXCLUDED.name,
+				description = EXCLUDED.description,
+				lead_id = EXCLUDED.lead_id,
+				lead_display = EXCLUDED.lead_display,
+				status = EXCLUDED.status,
+				start_date = EXCLUDED.start_date,
+				end_date = EXCLUDED.end_date,
+				updated_at_db = CURRENT_TIMESTAMP
+		`,
+			p.OrganizationID, p.TrackerID, p.Key, p.Name, p.Description, p.Lead.ID, p.Lead.Display,
+			p.Status, nullTime(p.StartDate), nullTime(p.EndDate),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert project %s: %w", p.Name, err)
+		}
+		projectIDs = append(projectIDs, p.TrackerID)
+	}
+
+	var componentIDs []string
+	for _, c := range refs.Components {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO components (
+				organization_id, tracker_id, name, queue_key, description, lead_id,
+				lead_display, assign_auto
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8
+			) ON CONFLICT (organization_id, tracker_id) DO UPDATE SET
+				name = EXCLUDED.name,
+				queue_key = EXCLUDED.queue_key,
+				description = EXCLUDED.description,
+				lead_id = EXCLUDED.lead_id,
+				lead_display = EXCLUDED.lead_display,
+				assign_auto = EXCLUDED.assign_auto,
+				updated_at_db = CURRENT_TIMESTAMP
+		`,
+			c.OrganizationID, c.TrackerID, c.Name, c.Queue.Key, c.Description, c.Lead.ID,
+			c.Lead.Display, c.AssignAuto,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert component %s: %w", c.Name, err)
+		}
+		componentIDs = append(componentIDs, c.TrackerID)
+	}
+
+	var versionIDs []string
+	for _, v := range refs.Versions {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO versions (
+				organization_id, tracker_id, name, queue_key, description, start_date,
+				due_date, released, archived
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9
+			) ON CONFLICT (organization_id, tracker_id) DO UPDATE SET
+				name = EXCLUDED.name,
+				queue_key = EXCLUDED.queue_key,
+				description = EXCLUDED.description,
+				start_date = EXCLUDED.start_date,
+				due_date = EXCLUDED.due_date,
+				released = EXCLUDED.released,
+				archived = EXCLUDED.archived,
+				updated_at_db = CURRENT_TIMESTAMP
+		`,
+			v.OrganizationID, v.TrackerID, v.Name, v.Queue.Key, v.Description, nullTime(v.StartDate),
+			nullTime(v.DueDate), v.Released, v.Archived,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert version %s: %w", v.Name, err)
+		}
+		versionIDs = append(versionIDs, v.TrackerID)
+	}
+
+	removed := map[string][]string{
+		"queues":     queueIDs,
+		"projects":   projectIDs,
+		"components": componentIDs,
+		"versions":   versionIDs,
+	}
+
+	dictionaries := map[string][]tracker.DictionaryItem{
+		"issue_types": refs.IssueTypes,
+		"priorities":  refs.Priorities,
+		"resolutions": refs.Resolutions,
+	}
+	for table, items := range dictionaries {
+		ids, err := saveDictionary(ctx, tx, table, items)
+		if err != nil {
+			return err
+		}
+		removed[table] = ids
+	}
+
+	// Remove entities that no longer exist in Tracker
+	for table, ids := range removed {
+		if err := deleteMissing(ctx, tx, table, s.orgID, ids); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	metrics.RowsUpserted.WithLabelValues("queues").Add(float64(len(refs.Queues)))
+	metrics.RowsUpserted.WithLabelValues("projects").Add(float64(len(refs.Projects)))
+	metrics.RowsUpserted.WithLabelValues("components").Add(float64(len(refs.Components)))
+	metrics.RowsUpserted.WithLabelValues("versions").Add(float64(len(refs.Versions)))
+	for table, items := range dictionaries {
+		metrics.RowsUpserted.WithLabelValues(table).Add(float64(len(items)))
+	}
+
+	slog.Info("Successfully saved reference data",
+		"queues", len(refs.Queues),
+		"projects", len(refs.Projects),
+		"components", len(refs.Components),
+		"versions", len(refs.Versions),
+		"issue_types", len(refs.IssueTypes),
+		"priorities", len(refs.Priorities),
+		"resolutions", len(refs.Resolutions))
+	return nil
+}
+
+// saveDictionary upserts the items of an issue type, priority or resolution
+// table and returns their tracker IDs
+func saveDictionary(ctx context.Context, tx pgx.Tx, table string, items []tracker.DictionaryItem) ([]string, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (
+			organization_id, tracker_id, key, name, name_en, name_ru, names, description, sort_order
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		) ON CONFLICT (organization_id, tracker_id) DO UPDATE SET
+			key = EXCLUDED.key,
+			name = EXCLUDED.name,
+			name_en = EXCLUDED.name_en,
+			name_ru = EXCLUDED.name_ru,
+			names = EXCLUDED.names,
+			description = EXCLUDED.description,
+			sort_order = EXCLUDED.sort_order,
+			updated_at_db = CURRENT_TIMESTAMP
+	`, table)
+
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		names, err := json.Marshal(item.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal names of %s %s: %w", table, item.Key, err)
+		}
+
+		_, err = tx.Exec(ctx, query,
+			item.OrganizationID, item.TrackerID, item.Key, item.Name.Get(tracker.DefaultLanguage),
+			nullString(item.Name["en"]), nullString(item.Name["ru"]), string(names), item.Description, item.Order,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert %s %s: %w", table, item.Key, err)
+		}
+		ids = append(ids, item.TrackerID)
+	}
+	return ids, nil
+}
+
+// nullString returns nil for an empty string so that it is stored as NULL
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// deleteMissing removes the rows of a reference table of the organization whose tracker ID is not in ids
+func deleteMissing(ctx context.Context, tx pgx.Tx, table, orgID string, ids []string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE organization_id = $2 AND NOT (tracker_id = ANY($1))`, table)
+	if _, err := tx.Exec(ctx, query, nonNilStrings(ids), orgID); err != nil {
+		return fmt.Errorf("failed to delete removed rows from %s: %w", table, err)
+	}
+	return nil
+}
+
+// nullTime returns nil for a zero time so that it is stored as NULL
+func nullTime(t tracker.Time) interface{} {
+	if t.Time().IsZero() {
+		return nil
+	}
+	return t.Time()
+}
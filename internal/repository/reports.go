@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/domain"
+)
+
+// GetCompletionTimes returns when issues first moved to a done status since
+// the given time, optionally limited to a queue
+func (s *Service) GetCompletionTimes(ctx context.Context, queueKey string, since time.Time) ([]time.Time, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT MIN(c.updated_at)
+		FROM changelog c
+		JOIN status_types st ON st.status_key = c.to_key
+		JOIN v_issues i ON i.key = c.issue_key
+		WHERE c.field_id = $1
+			AND st.status_type = $2
+			AND ($3 = '' OR i.queue_key = $3)
+		GROUP BY c.issue_key
+		HAVING MIN(c.updated_at) >= $4
+	`, statusFieldID, statusTypeDone, queueKey, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query completion times: %w", err)
+	}
+	defer rows.Close()
+
+	var times []time.Time
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, fmt.Errorf("failed to scan completion time: %w", err)
+		}
+		times = append(times, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate completion times: %w", err)
+	}
+	return times, nil
+}
+
+// GetStaleIssues returns the in-progress issues whose time in the current
+// status exceeds the given percentile (0-1) of the completed intervals in that
+// status of the same queue, optionally limited to a queue. Statuses with fewer
+// than minSamples completed intervals are skipped. The most overdue issues
+// come first.
+func (s *Service) GetStaleIssues(ctx context.Context, queueKey string, percentile float64, minSamples int) ([]domain.StaleIssue, error) {
+	rows, err := s.db.Query(ctx, `
+		WITH thresholds AS (
+			SELECT
+				i.queue_key,
+				si.status_key,
+				percentile_cont($2) WITHIN GROUP (ORDER BY si.duration_minutes) AS threshold_minutes,
+				COUNT(*) AS samples
+			FROM status_intervals si
+			JOIN v_issues i ON i.organization_id = si.organization_id AND i.key = si.issue_key
+			WHERE si.duration_minutes IS NOT NULL
+				AND ($1 = '' OR i.queue_key = $1)
+			GROUP BY i.queue_key, si.status_key
+		)
+		SELECT
+			i.key,
+			COALESCE(i.summary, ''),
+			i.queue_key,
+			i.status_key,
+			COALESCE(i.status_display, ''),
+			COALESCE(i.assignee_display, ''),
+			i.status_start_time,
+			EXTRACT(EPOCH FROM (now() - i.status_start_time))/60 AS age_minutes,
+			t.threshold_minutes,
+			t.samples
+		FROM v_issues i
+		JOIN status_types st ON st.status_key = i.status_key
+		JOIN thresholds t ON t.queue_key = i.queue_key AND t.status_key = i.status_key
+		WHERE st.status_type = $3
+			AND i.status_start_time IS NOT NULL
+			AND t.samples >= $4
+			AND EXTRACT(EPOCH FROM (now() - i.status_start_time))/60 > t.threshold_minutes
+		ORDER BY (EXTRACT(EPOCH FROM (now() - i.status_start_time))/60) / NULLIF(t.threshold_minutes, 0) DESC NULLS FIRST, i.key
+	`, queueKey, percentile, statusTypeInProgress, minSamples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale issues: %w", err)
+	}
+	defer rows.Close()
+
+	var issues []domain.StaleIssue
+	for rows.Next() {
+		var issue domain.StaleIssue
+		if err := rows.Scan(&issue.Key, &issue.Summary, &issue.QueueKey, &issue.StatusKey, &issue.StatusDisplay,
+			&issue.AssigneeDisplay, &issue.StatusStartTime, &issue.AgeMinutes, &issue.ThresholdMinutes,
+			&issue.Samples); err != nil {
+			return nil, fmt.Errorf("failed to scan stale issue: %w", err)
+		}
+		issues = append(issues, issue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate stale issues: %w", err)
+	}
+	return issues, nil
+}
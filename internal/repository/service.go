@@ -10,26 +10,36 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nemirlev/yc-tracker-go-data-import/internal/domain"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
 	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracker"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Service represents the repository service
 type Service struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	orgID string
 }
 
-// NewService creates a new repository service
-func NewService(db *pgxpool.Pool) domain.Repository {
-	return &Service{db: db}
+// NewService creates a new repository service for the organization. Reference
+// data of other organizations in the same database is left untouched.
+func NewService(db *pgxpool.Pool, orgID string) domain.Repository {
+	return &Service{db: db, orgID: orgID}
 }
 
 // SaveIssues saves issues to the database
-func (s *Service) SaveIssues(ctx context.Context, issues []tracker.Issue) error {
+func (s *Service) SaveIssues(ctx context.Context, issues []tracker.Issue) (domain.UpsertStats, error) {
 	slog.Info("Starting save issues", "total_issues", len(issues))
 
+	ctx, span := tracing.Start(ctx, "db.save_issues", attribute.Int("db.rows", len(issues)))
+	defer span.End()
+
+	var stats domain.UpsertStats
+
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return stats, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
@@ -50,23 +60,109 @@ func (s *Service) SaveIssues(ctx context.Context, issues []tracker.Issue) error
 			pending_reply_from, end_time, start_time, project_display,
 			voted_by_display, aliases, previous_queue_display, access,
 			resolved_at, resolved_by_display, resolution_display,
-			last_queue_display, status_type, team_number
+			last_queue_display, status_type, team_number,
+			status_key, status_id, type_key, type_id, priority_key, priority_id,
+			resolution_key, resolution_id, assignee_id, created_by_id, project_id,
+			epic_key, epic_id, parent_id, updated_by_id, resolved_by_id
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
 			$11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
 			$21, $22, $23, $24, $25, $26, $27, $28, $29, $30,
 			$31, $32, $33, $34, $35, $36, $37, $38, $39, $40,
-			$41, $42, $43, $44, $45, $46, $47, $48, $49, $50, $51,
-			$52, $53, $54, $55, $56, $57
+			$41, $42, $43, $44, $45, $46, $47, $48, $49, $50,
+			$51, $52, $53, $54, $55, $56, $57, $58, $59, $60,
+			$61, $62, $63, $64, $65, $66, $67, $68, $69, $70,
+			$71, $72, $73
 		) ON CONFLICT (tracker_id) DO UPDATE SET
+			organization_id = EXCLUDED.organization_id,
+			self = EXCLUDED.self,
+			key = EXCLUDED.key,
+			version = EXCLUDED.version,
+			story_points = EXCLUDED.story_points,
+			summary = EXCLUDED.summary,
+			status_start_time = EXCLUDED.status_start_time,
+			boards_names = EXCLUDED.boards_names,
+			created_at = EXCLUDED.created_at,
+			comment_without_external_message_count = EXCLUDED.comment_without_external_message_count,
+			votes = EXCLUDED.votes,
+			comment_with_external_message_count = EXCLUDED.comment_with_external_message_count,
+			deadline = EXCLUDED.deadline,
 			updated_at = EXCLUDED.updated_at,
+			favorite = EXCLUDED.favorite,
+			updated_by_display = EXCLUDED.updated_by_display,
+			type_display = EXCLUDED.type_display,
+			priority_display = EXCLUDED.priority_display,
+			created_by_display = EXCLUDED.created_by_display,
+			assignee_display = EXCLUDED.assignee_display,
+			queue_key = EXCLUDED.queue_key,
+			queue_display = EXCLUDED.queue_display,
+			status_display = EXCLUDED.status_display,
+			previous_status_display = EXCLUDED.previous_status_display,
+			parent_key = EXCLUDED.parent_key,
+			parent_display = EXCLUDED.parent_display,
+			components_display = EXCLUDED.components_display,
+			sprint_display = EXCLUDED.sprint_display,
+			epic_display = EXCLUDED.epic_display,
+			previous_status_last_assignee_display = EXCLUDED.previous_status_last_assignee_display,
+			original_estimation = EXCLUDED.original_estimation,
+			spent = EXCLUDED.spent,
+			tags = EXCLUDED.tags,
+			estimation = EXCLUDED.estimation,
+			checklist_done = EXCLUDED.checklist_done,
+			checklist_total = EXCLUDED.checklist_total,
+			email_created_by = EXCLUDED.email_created_by,
+			sla = EXCLUDED.sla,
+			email_to = EXCLUDED.email_to,
+			email_from = EXCLUDED.email_from,
+			last_comment_updated_at = EXCLUDED.last_comment_updated_at,
+			followers = EXCLUDED.followers,
+			pending_reply_from = EXCLUDED.pending_reply_from,
+			end_time = EXCLUDED.end_time,
+			start_time = EXCLUDED.start_time,
+			project_display = EXCLUDED.project_display,
+			voted_by_display = EXCLUDED.voted_by_display,
+			aliases = EXCLUDED.aliases,
+			previous_queue_display = EXCLUDED.previous_queue_display,
+			access = EXCLUDED.access,
+			resolved_at = EXCLUDED.resolved_at,
+			resolved_by_display = EXCLUDED.resolved_by_display,
+			resolution_display = EXCLUDED.resolution_display,
+			last_queue_display = EXCLUDED.last_queue_display,
+			status_type = EXCLUDED.status_type,
+			team_number = EXCLUDED.team_number,
+			status_key = EXCLUDED.status_key,
+			status_id = EXCLUDED.status_id,
+			type_key = EXCLUDED.type_key,
+			type_id = EXCLUDED.type_id,
+			priority_key = EXCLUDED.priority_key,
+			priority_id = EXCLUDED.priority_id,
+			resolution_key = EXCLUDED.resolution_key,
+			resolution_id = EXCLUDED.resolution_id,
+			assignee_id = EXCLUDED.assignee_id,
+			created_by_id = EXCLUDED.created_by_id,
+			project_id = EXCLUDED.project_id,
+			epic_key = EXCLUDED.epic_key,
+			epic_id = EXCLUDED.epic_id,
+			parent_id = EXCLUDED.parent_id,
+			updated_by_id = EXCLUDED.updated_by_id,
+			resolved_by_id = EXCLUDED.resolved_by_id,
 			updated_at_db = CURRENT_TIMESTAMP,
-			team_number = EXCLUDED.team_number
+			deleted_at = NULL,
+			out_of_scope_at = NULL
+		RETURNING (xmax = 0) AS inserted
 	`
 
 	_, err = tx.Prepare(ctx, "insert_issue", query)
 	if err != nil {
-		return fmt.Errorf("failed to prepare insert statement: %w", err)
+		return stats, fmt.Errorf("failed to prepare insert statement: %w", err)
+	}
+
+	if err := prepareIssueHistory(ctx, tx); err != nil {
+		return stats, err
+	}
+
+	if err := prepareIssueChildren(ctx, tx); err != nil {
+		return stats, err
 	}
 
 	// Insert each issue
@@ -181,7 +277,7 @@ func (s *Service) SaveIssues(ctx context.Context, issues []tracker.Issue) error
 				return issue.ChecklistTotal
 			}(),
 			issue.EmailCreatedBy,
-			strings.Join(getEntityDisplays(issue.SLA), ", "),
+			slaSummary(issue.SLA),
 			issue.EmailTo,
 			issue.EmailFrom,
 			func() interface{} {
@@ -220,24 +316,62 @@ func (s *Service) SaveIssues(ctx context.Context, issues []tracker.Issue) error
 			issue.LastQueue.Display,
 			issue.StatusType.Display,
 			issue.TeamNumber,
+			issue.Status.Key,
+			issue.Status.ID,
+			issue.Type.Key,
+			issue.Type.ID,
+			issue.Priority.Key,
+			issue.Priority.ID,
+			issue.Resolution.Key,
+			issue.Resolution.ID,
+			issue.Assignee.ID,
+			issue.CreatedBy.ID,
+			issue.Project.ID,
+			issue.Epic.Key,
+			issue.Epic.ID,
+			issue.Parent.ID,
+			issue.UpdatedBy.ID,
+			issue.ResolvedBy.ID,
+		}
+
+		var inserted bool
+		if err := tx.QueryRow(ctx, "insert_issue", params...).Scan(&inserted); err != nil {
+			return stats, fmt.Errorf("failed to insert issue %s: %w", issue.Key, err)
+		}
+		if inserted {
+			stats.Inserted++
+		} else {
+			stats.Updated++
 		}
 
-		_, err = tx.Exec(ctx, "insert_issue", params...)
+		versionFrom, err := saveIssueHistory(ctx, tx, issue)
 		if err != nil {
-			return fmt.Errorf("failed to insert issue %s: %w", issue.Key, err)
+			return stats, err
+		}
+		stats.HistoryChangedFrom = earliestTime(stats.HistoryChangedFrom, versionFrom)
+
+		if err := saveIssueChildren(ctx, tx, issue); err != nil {
+			return stats, err
 		}
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return stats, fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	metrics.RowsUpserted.WithLabelValues("issues").Add(float64(len(issues)))
 
-	slog.Info("Successfully saved all issues", "total_issues", len(issues))
-	return nil
+	slog.Info("Successfully saved all issues",
+		"total_issues", len(issues),
+		"inserted", stats.Inserted,
+		"updated", stats.Updated)
+	return stats, nil
 }
 
 // SaveChangelogs saves changelog entries to the database
 func (s *Service) SaveChangelogs(ctx context.Context, changelogs []tracker.Changelog) error {
+	ctx, span := tracing.Start(ctx, "db.save_changelogs", attribute.Int("db.rows", len(changelogs)))
+	defer span.End()
+
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -249,15 +383,22 @@ func (s *Service) SaveChangelogs(ctx context.Context, changelogs []tracker.Chang
 		INSERT INTO changelog (
 			organization_id, tracker_id, issue_key, updated_at,
 			updated_by_display, type, field_display, from_display,
-			to_display, worklog
+			to_display, worklog, field_id, from_id, from_key, to_id, to_key,
+			updated_by_id
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
 		) ON CONFLICT (tracker_id, field_display) DO UPDATE SET
 			updated_at = EXCLUDED.updated_at,
 			updated_by_display = EXCLUDED.updated_by_display,
 			from_display = EXCLUDED.from_display,
 			to_display = EXCLUDED.to_display,
-			worklog = EXCLUDED.worklog
+			worklog = EXCLUDED.worklog,
+			field_id = EXCLUDED.field_id,
+			from_id = EXCLUDED.from_id,
+			from_key = EXCLUDED.from_key,
+			to_id = EXCLUDED.to_id,
+			to_key = EXCLUDED.to_key,
+			updated_by_id = EXCLUDED.updated_by_id
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare insert statement: %w", err)
@@ -276,6 +417,12 @@ func (s *Service) SaveChangelogs(ctx context.Context, changelogs []tracker.Chang
 			changelog.FromDisplay,
 			changelog.ToDisplay,
 			changelog.Worklog,
+			changelog.FieldID,
+			changelog.FromID,
+			changelog.FromKey,
+			changelog.ToID,
+			changelog.ToKey,
+			changelog.UpdatedByID,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to insert changelog for issue %s: %w", changelog.IssueKey, err)
@@ -285,6 +432,7 @@ func (s *Service) SaveChangelogs(ctx context.Context, changelogs []tracker.Chang
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	metrics.RowsUpserted.WithLabelValues("changelog").Add(float64(len(changelogs)))
 
 	return nil
 }
@@ -308,10 +456,103 @@ func (s *Service) GetLastUpdateTime(ctx context.Context, issueKey string) (*time
 	return &lastUpdate, nil
 }
 
+// GetActiveIssueKeys returns keys of issues not marked as deleted or out of scope, indexed by tracker ID
+func (s *Service) GetActiveIssueKeys(ctx context.Context) (map[string]string, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT tracker_id, key
+		FROM issues
+		WHERE organization_id = $1
+			AND deleted_at IS NULL
+			AND out_of_scope_at IS NULL
+	`, s.orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active issues: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make(map[string]string)
+	for rows.Next() {
+		var trackerID, key string
+		if err := rows.Scan(&trackerID, &key); err != nil {
+			return nil, fmt.Errorf("failed to scan active issue: %w", err)
+		}
+		keys[trackerID] = key
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read active issues: %w", err)
+	}
+	return keys, nil
+}
+
+// MarkIssuesDeleted marks issues that no longer exist or are inaccessible in
+// Tracker, closes their open history version and drops their flow metrics
+func (s *Service) MarkIssuesDeleted(ctx context.Context, trackerIDs []string, at time.Time) error {
+	if len(trackerIDs) == 0 {
+		return nil
+	}
+	_, err := s.db.Exec(ctx, `
+		WITH closed_history AS (
+			UPDATE issue_history SET
+				valid_to = GREATEST($2, valid_from)
+			WHERE tracker_id = ANY($1)
+				AND organization_id = $3
+				AND valid_to IS NULL
+		), removed_flow_metrics AS (
+			DELETE FROM issue_flow_metrics
+			WHERE organization_id = $3
+				AND issue_key IN (SELECT key FROM issues WHERE tracker_id = ANY($1) AND organization_id = $3)
+		)
+		UPDATE issues SET
+			deleted_at = $2,
+			updated_at_db = CURRENT_TIMESTAMP
+		WHERE tracker_id = ANY($1)
+			AND organization_id = $3
+			AND deleted_at IS NULL
+	`, trackerIDs, at, s.orgID)
+	if err != nil {
+		return fmt.Errorf("failed to mark issues as deleted: %w", err)
+	}
+	return nil
+}
+
+// MarkIssuesOutOfScope marks issues that exist in Tracker but no longer match
+// the filter, closes their open history version and drops their flow metrics
+func (s *Service) MarkIssuesOutOfScope(ctx context.Context, trackerIDs []string, at time.Time) error {
+	if len(trackerIDs) == 0 {
+		return nil
+	}
+	_, err := s.db.Exec(ctx, `
+		WITH closed_history AS (
+			UPDATE issue_history SET
+				valid_to = GREATEST($2, valid_from)
+			WHERE tracker_id = ANY($1)
+				AND organization_id = $3
+				AND valid_to IS NULL
+		), removed_flow_metrics AS (
+			DELETE FROM issue_flow_metrics
+			WHERE organization_id = $3
+				AND issue_key IN (SELECT key FROM issues WHERE tracker_id = ANY($1) AND organization_id = $3)
+		)
+		UPDATE issues SET
+			out_of_scope_at = $2,
+			updated_at_db = CURRENT_TIMESTAMP
+		WHERE tracker_id = ANY($1)
+			AND organization_id = $3
+			AND out_of_scope_at IS NULL
+	`, trackerIDs, at, s.orgID)
+	if err != nil {
+		return fmt.Errorf("failed to mark issues as out of scope: %w", err)
+	}
+	return nil
+}
+
 // SaveStatusTypes saves status types to the database
 func (s *Service) SaveStatusTypes(ctx context.Context, statusTypes []tracker.StatusType) error {
 	slog.Info("Starting save status types", "total_status_types", len(statusTypes))
 
+	ctx, span := tracing.Start(ctx, "db.save_status_types", attribute.Int("db.rows", len(statusTypes)))
+	defer span.End()
+
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -354,6 +595,7 @@ func (s *Service) SaveStatusTypes(ctx context.Context, statusTypes []tracker.Sta
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	metrics.RowsUpserted.WithLabelValues("status_types").Add(float64(len(statusTypes)))
 
 	slog.Info("Successfully saved all status types", "total_status_types", len(statusTypes))
 	return nil
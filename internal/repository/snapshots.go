@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/calendar"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
+)
+
+// UpdateDailySnapshots fills queue_daily_snapshot from the status intervals.
+// The first run backfills every day since the oldest issue; later runs
+// recompute the days from the last stored snapshot, or from changedFrom when
+// the status intervals or issue history changed before it, up to today. Days
+// end at midnight in the time zone of the calendar. Deleted and out of scope
+// issues are counted until the day they were removed.
+func (s *Service) UpdateDailySnapshots(ctx context.Context, changedFrom *time.Time, cal *calendar.Calendar) error {
+	ctx, span := tracing.Start(ctx, "db.update_daily_snapshots")
+	defer span.End()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	timeZone := cal.Location().String()
+
+	// Start from the last stored day or the earliest changed day, whichever is
+	// earlier, or, on the first run, from the oldest status interval
+	var from *time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT CASE
+			WHEN EXISTS (SELECT 1 FROM queue_daily_snapshot) THEN LEAST(
+				(SELECT MAX(snapshot_date) FROM queue_daily_snapshot),
+				($2::timestamptz AT TIME ZONE $1)::date
+			)
+			ELSE (SELECT MIN(entered_at AT TIME ZONE $1)::date FROM status_intervals)
+		END
+	`, timeZone, changedFrom).Scan(&from)
+	if err != nil {
+		return fmt.Errorf("failed to get first snapshot date: %w", err)
+	}
+	if from == nil {
+		slog.Info("No status intervals to build daily snapshots from")
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM queue_daily_snapshot WHERE snapshot_date >= $1`, *from); err != nil {
+		return fmt.Errorf("failed to clear daily snapshots: %w", err)
+	}
+
+	// Every status interval contributes to the days whose end falls into it.
+	// Queue and story points come from the issue version valid at the end of
+	// the day, or the first version for days before issue history starts, so
+	// that later edits do not rewrite older days.
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO queue_daily_snapshot (
+			snapshot_date, queue_key, status_key, status_display, status_type, issues, story_points
+		)
+		SELECT
+			d.snapshot_date,
+			COALESCE(h.queue_key, i.queue_key) AS queue_key,
+			d.status_key,
+			MAX(d.status_display),
+			MAX(d.status_type),
+			COUNT(*),
+			COALESCE(SUM(COALESCE(h.story_points, i.story_points)), 0)
+		FROM (
+			SELECT
+				si.organization_id,
+				si.issue_key,
+				si.status_key,
+				si.status_display,
+				si.status_type,
+				si.exited_at,
+				day::date AS snapshot_date,
+				(day::date + 1)::timestamp AT TIME ZONE $2 AS day_end
+			FROM status_intervals si
+			CROSS JOIN LATERAL generate_series(
+				GREATEST((si.entered_at AT TIME ZONE $2)::date, $1::date),
+				LEAST((COALESCE(si.exited_at, now()) AT TIME ZONE $2)::date, (now() AT TIME ZONE $2)::date),
+				interval '1 day'
+			) AS day
+			WHERE COALESCE(si.exited_at, now()) >= $1::timestamp AT TIME ZONE $2
+		) d
+		JOIN v_issues_all i ON i.organization_id = d.organization_id AND i.key = d.issue_key
+		LEFT JOIN LATERAL (
+			SELECT ih.queue_key, ih.story_points
+			FROM issue_history ih
+			WHERE ih.organization_id = i.organization_id
+				AND ih.tracker_id = i.tracker_id
+				AND (ih.valid_to IS NULL OR ih.valid_to > d.day_end)
+			ORDER BY ih.valid_from
+			LIMIT 1
+		) h ON true
+		WHERE (d.exited_at IS NULL OR d.exited_at > d.day_end)
+			AND (COALESCE(i.deleted_at, i.out_of_scope_at) IS NULL OR COALESCE(i.deleted_at, i.out_of_scope_at) > d.day_end)
+			AND COALESCE(h.queue_key, i.queue_key) IS NOT NULL
+		GROUP BY d.snapshot_date, COALESCE(h.queue_key, i.queue_key), d.status_key
+	`, *from, timeZone)
+	if err != nil {
+		return fmt.Errorf("failed to insert daily snapshots: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	metrics.RowsUpserted.WithLabelValues("queue_daily_snapshot").Add(float64(tag.RowsAffected()))
+
+	slog.Info("Successfully updated daily snapshots", "from", from.Format("2006-01-02"), "rows", tag.RowsAffected())
+	return nil
+}
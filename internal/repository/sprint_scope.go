@@ -0,0 +1,404 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/calendar"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
+)
+
+// storyPointsFieldID is the changelog field ID of the story points field
+const storyPointsFieldID = "storyPoints"
+
+// sprintBounds is the start and end of a sprint
+type sprintBounds struct {
+	id      string
+	boardID int
+	start   time.Time
+	end     time.Time
+}
+
+// storyPointsChange is a story points field change from the changelog
+type storyPointsChange struct {
+	at   time.Time
+	from float64
+	to   float64
+}
+
+// sprintIssue is the sprint history of an issue used for sprint scope
+type sprintIssue struct {
+	memberships []sprintMembership
+	points      float64
+	changes     []storyPointsChange
+	done        []statusInterval
+}
+
+// pointsAt returns the story points of the issue at t. Before the first
+// change the issue had the value the first change started from.
+func (i *sprintIssue) pointsAt(t time.Time) float64 {
+	if len(i.changes) == 0 {
+		return i.points
+	}
+	points := i.changes[0].from
+	for _, c := range i.changes {
+		if c.at.After(t) {
+			break
+		}
+		points = c.to
+	}
+	return points
+}
+
+// inSprintAt reports whether the issue belonged to the sprint at t
+func (i *sprintIssue) inSprintAt(sprintID string, t time.Time) bool {
+	for _, m := range i.memberships {
+		if m.sprintID == sprintID && !m.addedAt.After(t) && (m.removedAt == nil || m.removedAt.After(t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// doneAt reports whether the issue was in a done status at t
+func (i *sprintIssue) doneAt(t time.Time) bool {
+	for _, d := range i.done {
+		if !d.enteredAt.After(t) && (d.exitedAt == nil || d.exitedAt.After(t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sprintScope is the committed, changed and completed scope of a sprint
+type sprintScope struct {
+	committedIssues   int
+	committedPoints   float64
+	addedIssues       int
+	addedPoints       float64
+	removedIssues     int
+	removedPoints     float64
+	reestimatedPoints float64
+	completedIssues   int
+	completedPoints   float64
+}
+
+// sprintDay is the burndown state of a sprint at the end of a day
+type sprintDay struct {
+	day             time.Time
+	scopeIssues     int
+	scopePoints     float64
+	completedIssues int
+	completedPoints float64
+	idealPoints     float64
+}
+
+// UpdateSprintScope rebuilds sprint_scope and sprint_burndown for every
+// started sprint from the sprint membership, the story points changelog and
+// the done status intervals. Burndown days end at midnight in the time zone
+// of the calendar; the days of a running sprint end at the current day.
+func (s *Service) UpdateSprintScope(ctx context.Context, cal *calendar.Calendar) error {
+	ctx, span := tracing.Start(ctx, "db.update_sprint_scope")
+	defer span.End()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sprints, err := loadSprintBounds(ctx, tx, cal.Location().String())
+	if err != nil {
+		return err
+	}
+	issues, err := loadSprintIssues(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM sprint_burndown`); err != nil {
+		return fmt.Errorf("failed to clear sprint burndown: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM sprint_scope`); err != nil {
+		return fmt.Errorf("failed to clear sprint scope: %w", err)
+	}
+
+	// Issues of every sprint
+	bySprint := make(map[string][]*sprintIssue)
+	for _, issue := range issues {
+		seen := make(map[string]bool)
+		for _, m := range issue.memberships {
+			if !seen[m.sprintID] {
+				seen[m.sprintID] = true
+				bySprint[m.sprintID] = append(bySprint[m.sprintID], issue)
+			}
+		}
+	}
+
+	now := time.Now()
+	days := 0
+	for _, sp := range sprints {
+		scope := buildSprintScope(sp, bySprint[sp.id], now)
+		_, err := tx.Exec(ctx, `
+			INSERT INTO sprint_scope (
+				sprint_id, board_id, sprint_start, sprint_end, committed_issues, committed_points,
+				added_issues, added_points, removed_issues, removed_points, reestimated_points,
+				completed_issues, completed_points
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+			)
+		`, sp.id, sp.boardID, sp.start, sp.end, scope.committedIssues, scope.committedPoints,
+			scope.addedIssues, scope.addedPoints, scope.removedIssues, scope.removedPoints,
+			scope.reestimatedPoints, scope.completedIssues, scope.completedPoints)
+		if err != nil {
+			return fmt.Errorf("failed to insert scope of sprint %s: %w", sp.id, err)
+		}
+
+		for _, d := range buildSprintBurndown(sp, bySprint[sp.id], scope.committedPoints, cal, now) {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO sprint_burndown (
+					sprint_id, day, scope_issues, scope_points, completed_issues, completed_points,
+					remaining_issues, remaining_points, ideal_points
+				) VALUES (
+					$1, $2, $3, $4, $5, $6, $7, $8, $9
+				)
+			`, sp.id, d.day, d.scopeIssues, d.scopePoints, d.completedIssues, d.completedPoints,
+				d.scopeIssues-d.completedIssues, d.scopePoints-d.completedPoints, d.idealPoints)
+			if err != nil {
+				return fmt.Errorf("failed to insert burndown of sprint %s: %w", sp.id, err)
+			}
+			days++
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	metrics.RowsUpserted.WithLabelValues("sprint_scope").Add(float64(len(sprints)))
+	metrics.RowsUpserted.WithLabelValues("sprint_burndown").Add(float64(days))
+
+	slog.Info("Successfully updated sprint scope", "sprints", len(sprints), "burndown_days", days)
+	return nil
+}
+
+// loadSprintBounds reads the start and end of every started sprint. Sprints
+// without start or end times start at midnight of their start date and end at
+// the end of their end date in the given time zone.
+func loadSprintBounds(ctx context.Context, tx pgx.Tx, timeZone string) ([]sprintBounds, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT tracker_id, board_id,
+			COALESCE(start_date_time, start_date::timestamp AT TIME ZONE $1),
+			COALESCE(end_date_time, (end_date + 1)::timestamp AT TIME ZONE $1)
+		FROM sprints
+		WHERE COALESCE(start_date_time, start_date::timestamp AT TIME ZONE $1) <= now()
+			AND COALESCE(end_date_time, (end_date + 1)::timestamp AT TIME ZONE $1) IS NOT NULL
+	`, timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sprints: %w", err)
+	}
+	defer rows.Close()
+
+	var sprints []sprintBounds
+	for rows.Next() {
+		var sp sprintBounds
+		if err := rows.Scan(&sp.id, &sp.boardID, &sp.start, &sp.end); err != nil {
+			return nil, fmt.Errorf("failed to scan sprint: %w", err)
+		}
+		if sp.end.After(sp.start) {
+			sprints = append(sprints, sp)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sprints: %w", err)
+	}
+	return sprints, nil
+}
+
+// loadSprintIssues reads the sprint membership, current and changed story
+// points and done status intervals of every issue that was ever in a sprint
+func loadSprintIssues(ctx context.Context, tx pgx.Tx) (map[string]*sprintIssue, error) {
+	issues := make(map[string]*sprintIssue)
+
+	rows, err := tx.Query(ctx, `
+		SELECT m.issue_key, m.sprint_id, m.added_at, m.removed_at, COALESCE(i.story_points, 0)::float8
+		FROM issue_sprint_membership m
+		LEFT JOIN v_issues i ON i.key = m.issue_key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sprint membership: %w", err)
+	}
+	for rows.Next() {
+		var m sprintMembership
+		var points float64
+		if err := rows.Scan(&m.issueKey, &m.sprintID, &m.addedAt, &m.removedAt, &points); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan sprint membership: %w", err)
+		}
+		issue, ok := issues[m.issueKey]
+		if !ok {
+			issue = &sprintIssue{points: points}
+			issues[m.issueKey] = issue
+		}
+		issue.memberships = append(issue.memberships, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sprint membership: %w", err)
+	}
+
+	rows, err = tx.Query(ctx, `
+		SELECT issue_key, updated_at, COALESCE(from_display, ''), COALESCE(to_display, '')
+		FROM changelog
+		WHERE field_id = $1 AND issue_key IN (SELECT issue_key FROM issue_sprint_membership)
+		ORDER BY issue_key, updated_at, tracker_id
+	`, storyPointsFieldID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query story points changes: %w", err)
+	}
+	for rows.Next() {
+		var key, from, to string
+		var at time.Time
+		if err := rows.Scan(&key, &at, &from, &to); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan story points change: %w", err)
+		}
+		if issue, ok := issues[key]; ok {
+			issue.changes = append(issue.changes, storyPointsChange{at: at, from: parsePoints(from), to: parsePoints(to)})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate story points changes: %w", err)
+	}
+
+	rows, err = tx.Query(ctx, `
+		SELECT issue_key, entered_at, exited_at
+		FROM status_intervals
+		WHERE status_type = $1 AND issue_key IN (SELECT issue_key FROM issue_sprint_membership)
+	`, statusTypeDone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query done intervals: %w", err)
+	}
+	for rows.Next() {
+		var d statusInterval
+		if err := rows.Scan(&d.issueKey, &d.enteredAt, &d.exitedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan done interval: %w", err)
+		}
+		if issue, ok := issues[d.issueKey]; ok {
+			issue.done = append(issue.done, d)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate done intervals: %w", err)
+	}
+
+	return issues, nil
+}
+
+// buildSprintScope counts the scope of a sprint from the issues that were ever in it. Issues in the sprint at the
+// start are committed; issues joining or leaving it before the end are added
+// or removed with their story points at that moment; story points changes of
+// issues in the sprint are re-estimation. Issues in the sprint and done at the
+// end, or now for a running sprint, are completed.
+func buildSprintScope(sp sprintBounds, issues []*sprintIssue, now time.Time) sprintScope {
+	var scope sprintScope
+	end := sp.end
+	if now.Before(end) {
+		end = now
+	}
+
+	for _, issue := range issues {
+		if issue.inSprintAt(sp.id, sp.start) {
+			scope.committedIssues++
+			scope.committedPoints += issue.pointsAt(sp.start)
+		}
+
+		added, removed := false, false
+		for _, m := range issue.memberships {
+			if m.sprintID != sp.id {
+				continue
+			}
+			if m.addedAt.After(sp.start) && m.addedAt.Before(end) {
+				added = true
+				scope.addedPoints += issue.pointsAt(m.addedAt)
+			}
+			if m.removedAt != nil && m.removedAt.After(sp.start) && m.removedAt.Before(end) {
+				removed = true
+				scope.removedPoints += issue.pointsAt(*m.removedAt)
+			}
+		}
+		if added {
+			scope.addedIssues++
+		}
+		if removed {
+			scope.removedIssues++
+		}
+
+		for _, c := range issue.changes {
+			if c.at.After(sp.start) && c.at.Before(end) && issue.inSprintAt(sp.id, c.at) {
+				scope.reestimatedPoints += c.to - c.from
+			}
+		}
+
+		if issue.inSprintAt(sp.id, end) && issue.doneAt(end) {
+			scope.completedIssues++
+			scope.completedPoints += issue.pointsAt(end)
+		}
+	}
+	return scope
+}
+
+// buildSprintBurndown returns the scope and completed work of a sprint at the
+// end of every day from its start up to its end or today. The ideal line
+// burns the committed points down linearly over the sprint.
+func buildSprintBurndown(sp sprintBounds, issues []*sprintIssue, committed float64, cal *calendar.Calendar, now time.Time) []sprintDay {
+	loc := cal.Location()
+	start := sp.start.In(loc)
+	length := sp.end.Sub(sp.start)
+
+	var result []sprintDay
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); day.Before(sp.end) && !day.After(now); day = day.AddDate(0, 0, 1) {
+		at := day.AddDate(0, 0, 1)
+		if at.After(sp.end) {
+			at = sp.end
+		}
+		if at.After(now) {
+			at = now
+		}
+
+		d := sprintDay{day: day}
+		for _, issue := range issues {
+			if !issue.inSprintAt(sp.id, at) {
+				continue
+			}
+			points := issue.pointsAt(at)
+			d.scopeIssues++
+			d.scopePoints += points
+			if issue.doneAt(at) {
+				d.completedIssues++
+				d.completedPoints += points
+			}
+		}
+		elapsed := float64(at.Sub(sp.start)) / float64(length)
+		d.idealPoints = committed * (1 - min(max(elapsed, 0), 1))
+		result = append(result, d)
+	}
+	return result
+}
+
+// parsePoints parses a story points changelog value, treating empty values as zero
+func parsePoints(value string) float64 {
+	points, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", "."), 64)
+	if err != nil {
+		return 0
+	}
+	return points
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/calendar"
+)
+
+// sprintFixture returns a three day sprint and the issues that were ever in it:
+//   - TEST-1 is committed and done on the second day
+//   - TEST-2 is committed and removed on the second day
+//   - TEST-3 is added on the second day and re-estimated from 1 to 5 on the third
+//   - TEST-4 is done in another sprint
+func sprintFixture() (sprintBounds, []*sprintIssue) {
+	sp := sprintBounds{id: "1", boardID: 12, start: at(4, 0), end: at(7, 0)}
+	issues := []*sprintIssue{
+		{
+			memberships: []sprintMembership{{issueKey: "TEST-1", sprintID: "1", addedAt: at(1, 10)}},
+			points:      3,
+			done:        []statusInterval{{statusType: statusTypeDone, enteredAt: at(5, 12)}},
+		},
+		{
+			memberships: []sprintMembership{{issueKey: "TEST-2", sprintID: "1", addedAt: at(1, 10), removedAt: atPtr(5, 10)}},
+			points:      2,
+		},
+		{
+			memberships: []sprintMembership{{issueKey: "TEST-3", sprintID: "1", addedAt: at(5, 10)}},
+			points:      5,
+			changes:     []storyPointsChange{{at: at(6, 10), from: 1, to: 5}},
+		},
+		{
+			memberships: []sprintMembership{{issueKey: "TEST-4", sprintID: "2", addedAt: at(1, 10)}},
+			points:      8,
+			done:        []statusInterval{{statusType: statusTypeDone, enteredAt: at(5, 12)}},
+		},
+	}
+	return sp, issues
+}
+
+func TestBuildSprintScope(t *testing.T) {
+	sp, issues := sprintFixture()
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want sprintScope
+	}{
+		{
+			name: "finished sprint",
+			now:  at(10, 0),
+			want: sprintScope{
+				committedIssues:   2,
+				committedPoints:   5,
+				addedIssues:       1,
+				addedPoints:       1,
+				removedIssues:     1,
+				removedPoints:     2,
+				reestimatedPoints: 4,
+				completedIssues:   1,
+				completedPoints:   3,
+			},
+		},
+		{
+			name: "running sprint counts up to now",
+			now:  at(5, 11),
+			want: sprintScope{
+				committedIssues: 2,
+				committedPoints: 5,
+				addedIssues:     1,
+				addedPoints:     1,
+				removedIssues:   1,
+				removedPoints:   2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildSprintScope(sp, issues, tt.now); got != tt.want {
+				t.Errorf("buildSprintScope() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildSprintBurndown(t *testing.T) {
+	sp, issues := sprintFixture()
+
+	newCalendar := func(timeZone string) *calendar.Calendar {
+		cal, err := calendar.New(calendar.Config{TimeZone: timeZone, WorkStart: "09:00", WorkEnd: "18:00"})
+		if err != nil {
+			t.Fatalf("failed to create calendar: %v", err)
+		}
+		return cal
+	}
+	moscow := newCalendar("Europe/Moscow")
+	moscowDay := func(day int) time.Time {
+		return time.Date(2024, 3, day, 0, 0, 0, 0, moscow.Location())
+	}
+
+	tests := []struct {
+		name string
+		cal  *calendar.Calendar
+		now  time.Time
+		want []sprintDay
+	}{
+		{
+			name: "finished sprint",
+			cal:  newCalendar("UTC"),
+			now:  at(10, 0),
+			want: []sprintDay{
+				{day: at(4, 0), scopeIssues: 2, scopePoints: 5, idealPoints: 5 * 2.0 / 3},
+				{day: at(5, 0), scopeIssues: 2, scopePoints: 4, completedIssues: 1, completedPoints: 3, idealPoints: 5 * 1.0 / 3},
+				{day: at(6, 0), scopeIssues: 2, scopePoints: 8, completedIssues: 1, completedPoints: 3, idealPoints: 0},
+			},
+		},
+		{
+			name: "running sprint ends at now",
+			cal:  newCalendar("UTC"),
+			now:  at(5, 11),
+			want: []sprintDay{
+				{day: at(4, 0), scopeIssues: 2, scopePoints: 5, idealPoints: 5 * 2.0 / 3},
+				{day: at(5, 0), scopeIssues: 2, scopePoints: 4, idealPoints: 5 * 37.0 / 72},
+			},
+		},
+		{
+			name: "days end at midnight in the calendar time zone",
+			cal:  moscow,
+			now:  at(10, 0),
+			want: []sprintDay{
+				{day: moscowDay(4), scopeIssues: 2, scopePoints: 5, idealPoints: 5 * 51.0 / 72},
+				{day: moscowDay(5), scopeIssues: 2, scopePoints: 4, completedIssues: 1, completedPoints: 3, idealPoints: 5 * 27.0 / 72},
+				{day: moscowDay(6), scopeIssues: 2, scopePoints: 8, completedIssues: 1, completedPoints: 3, idealPoints: 5 * 3.0 / 72},
+				{day: moscowDay(7), scopeIssues: 2, scopePoints: 8, completedIssues: 1, completedPoints: 3, idealPoints: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildSprintBurndown(sp, issues, 5, tt.cal, tt.now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d days, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				d := got[i]
+				if !d.day.Equal(want.day) || d.scopeIssues != want.scopeIssues || d.scopePoints != want.scopePoints ||
+					d.completedIssues != want.completedIssues || d.completedPoints != want.completedPoints ||
+					math.Abs(d.idealPoints-want.idealPoints) > 1e-9 {
+					t.Errorf("day %d = %+v, want %+v", i, d, want)
+				}
+			}
+		})
+	}
+}
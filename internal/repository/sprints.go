@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracker"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// sprintFieldID is the changelog field ID of the issue sprint field
+const sprintFieldID = "sprint"
+
+// SaveBoards replaces the agile boards and sprints of the organization
+func (s *Service) SaveBoards(ctx context.Context, boards []tracker.BoardInfo, sprints []tracker.Sprint) error {
+	ctx, span := tracing.Start(ctx, "db.save_boards",
+		attribute.Int("db.boards", len(boards)),
+		attribute.Int("db.sprints", len(sprints)),
+	)
+	defer span.End()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	boardIDs := make([]int32, 0, len(boards))
+	for _, b := range boards {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO boards (
+				organization_id, board_id, name, query, use_ranking
+			) VALUES (
+				$1, $2, $3, $4, $5
+			) ON CONFLICT (organization_id, board_id) DO UPDATE SET
+				name = EXCLUDED.name,
+				query = EXCLUDED.query,
+				use_ranking = EXCLUDED.use_ranking,
+				updated_at_db = CURRENT_TIMESTAMP
+		`, b.OrganizationID, b.ID, b.Name, b.Query, b.UseRanking)
+		if err != nil {
+			return fmt.Errorf("failed to insert board %d: %w", b.ID, err)
+		}
+		boardIDs = append(boardIDs, int32(b.ID))
+	}
+
+	var sprintIDs []string
+	for _, sp := range sprints {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO sprints (
+				organization_id, tracker_id, board_id, name, status, archived, created_by_id,
+				created_at, start_date, end_date, start_date_time, end_date_time
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+			) ON CONFLICT (organization_id, tracker_id) DO UPDATE SET
+				board_id = EXCLUDED.board_id,
+				name = EXCLUDED.name,
+				status = EXCLUDED.status,
+				archived = EXCLUDED.archived,
+				created_by_id = EXCLUDED.created_by_id,
+				created_at = EXCLUDED.created_at,
+				start_date = EXCLUDED.start_date,
+				end_date = EXCLUDED.end_date,
+				start_date_time = EXCLUDED.start_date_time,
+				end_date_time = EXCLUDED.end_date_time,
+				updated_at_db = CURRENT_TIMESTAMP
+		`,
+			sp.OrganizationID, sp.TrackerID, sp.BoardID, sp.Name, sp.Status, sp.Archived, sp.CreatedBy.ID,
+			nullTime(sp.CreatedAt), nullTime(sp.StartDate), nullTime(sp.EndDate),
+			nullTime(sp.StartDateTime), nullTime(sp.EndDateTime),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert sprint %s: %w", sp.TrackerID, err)
+		}
+		sprintIDs = append(sprintIDs, sp.TrackerID)
+	}
+
+	// Remove boards and sprints that no longer exist in Tracker
+	_, err = tx.Exec(ctx, `DELETE FROM boards WHERE organization_id = $2 AND NOT (board_id = ANY($1))`, boardIDs, s.orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete removed boards: %w", err)
+	}
+	if err := deleteMissing(ctx, tx, "sprints", s.orgID, sprintIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	metrics.RowsUpserted.WithLabelValues("boards").Add(float64(len(boards)))
+	metrics.RowsUpserted.WithLabelValues("sprints").Add(float64(len(sprints)))
+
+	slog.Info("Successfully saved boards and sprints", "boards", len(boards), "sprints", len(sprints))
+	return nil
+}
+
+// sprintMembership is a period during which an issue belonged to a sprint
+type sprintMembership struct {
+	issueKey  string
+	sprintID  string
+	addedAt   time.Time
+	removedAt *time.Time
+}
+
+// sprintChange is a change of the sprint field of an issue
+type sprintChange struct {
+	at   time.Time
+	from []string
+	to   []string
+}
+
+// UpdateSprintMembership rebuilds the issue_sprint_membership rows of the
+// given issues from the stored sprint field changelog
+func (s *Service) UpdateSprintMembership(ctx context.Context, issueKeys []string) error {
+	ctx, span := tracing.Start(ctx, "db.update_sprint_membership", attribute.Int("db.issues", len(issueKeys)))
+	defer span.End()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	keys := nonNilStrings(issueKeys)
+
+	// Creation time and current sprints of each issue
+	createdAt := make(map[string]time.Time)
+	current := make(map[string][]string)
+	rows, err := tx.Query(ctx, `
+		SELECT i.key, i.created_at, COALESCE(array_agg(s.sprint_id) FILTER (WHERE s.sprint_id IS NOT NULL), '{}')
+		FROM issues i
+		LEFT JOIN issue_sprints s ON s.issue_tracker_id = i.tracker_id
+		WHERE i.key = ANY($1)
+		GROUP BY i.key, i.created_at
+	`, keys)
+	if err != nil {
+		return fmt.Errorf("failed to query issue sprints: %w", err)
+	}
+	for rows.Next() {
+		var key string
+		var created *time.Time
+		var sprints []string
+		if err := rows.Scan(&key, &created, &sprints); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan issue sprints: %w", err)
+		}
+		if created != nil {
+			createdAt[key] = *created
+		}
+		current[key] = sprints
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate issue sprints: %w", err)
+	}
+
+	// Sprint field changes of each issue in chronological order
+	changes := make(map[string][]sprintChange)
+	rows, err = tx.Query(ctx, `
+		SELECT issue_key, updated_at, COALESCE(from_id, ''), COALESCE(to_id, '')
+		FROM changelog
+		WHERE issue_key = ANY($1) AND field_id = $2
+		ORDER BY issue_key, updated_at, tracker_id
+	`, keys, sprintFieldID)
+	if err != nil {
+		return fmt.Errorf("failed to query sprint changes: %w", err)
+	}
+	for rows.Next() {
+		var key, from, to string
+		var at time.Time
+		if err := rows.Scan(&key, &at, &from, &to); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan sprint change: %w", err)
+		}
+		changes[key] = append(changes[key], sprintChange{at: at, from: splitIDs(from), to: splitIDs(to)})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate sprint changes: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM issue_sprint_membership WHERE issue_key = ANY($1)`, keys); err != nil {
+		return fmt.Errorf("failed to clear sprint membership: %w", err)
+	}
+
+	total := 0
+	for key := range current {
+		for _, m := range buildSprintMembership(key, createdAt[key], changes[key], current[key]) {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO issue_sprint_membership (issue_key, sprint_id, added_at, removed_at)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT DO NOTHING
+			`, m.issueKey, m.sprintID, m.addedAt, m.removedAt)
+			if err != nil {
+				return fmt.Errorf("failed to insert sprint membership of issue %s: %w", key, err)
+			}
+			total++
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	metrics.RowsUpserted.WithLabelValues("issue_sprint_membership").Add(float64(total))
+
+	slog.Info("Successfully updated sprint membership", "issues", len(current), "rows", total)
+	return nil
+}
+
+// buildSprintMembership replays the sprint field changes of an issue. Sprints
+// the issue was in before its first recorded change, or is in now without a
+// recorded change, are counted from the issue creation time.
+func buildSprintMembership(issueKey string, createdAt time.Time, changes []sprintChange, current []string) []sprintMembership {
+	var result []sprintMembership
+	open := make(map[string]time.Time)
+
+	closeMembership := func(sprintID string, at time.Time) {
+		added, ok := open[sprintID]
+		if !ok {
+			added = createdAt
+		}
+		removed := at
+		result = append(result, sprintMembership{issueKey: issueKey, sprintID: sprintID, addedAt: added, removedAt: &removed})
+		delete(open, sprintID)
+	}
+
+	for i, change := range changes {
+		if i == 0 {
+			for _, id := range change.from {
+				open[id] = createdAt
+			}
+		}
+
+		to := make(map[string]bool, len(change.to))
+		for _, id := range change.to {
+			to[id] = true
+		}
+		for _, id := range change.from {
+			if !to[id] {
+				closeMembership(id, change.at)
+			}
+		}
+		for _, id := range change.to {
+			if _, ok := open[id]; !ok {
+				open[id] = change.at
+			}
+		}
+	}
+
+	for _, id := range current {
+		if _, ok := open[id]; !ok {
+			open[id] = createdAt
+		}
+	}
+	for id, added := range open {
+		result = append(result, sprintMembership{issueKey: issueKey, sprintID: id, addedAt: added})
+	}
+	return result
+}
+
+// splitIDs splits a comma-joined list of changelog IDs
+func splitIDs(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/calendar"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// statusFieldID is the changelog field ID of status changes
+const statusFieldID = "status"
+
+// issueStatus is the creation time and current status of an issue
+type issueStatus struct {
+	createdAt time.Time
+	status    statusRef
+}
+
+// statusRef is a status key with its display name
+type statusRef struct {
+	key     string
+	display string
+}
+
+// statusChange is a status field change from the changelog
+type statusChange struct {
+	at   time.Time
+	from statusRef
+	to   statusRef
+}
+
+// statusInterval is a period an issue spent in a status
+type statusInterval struct {
+	issueKey   string
+	position   int
+	status     statusRef
+	statusType string
+	enteredAt  time.Time
+	exitedAt   *time.Time
+}
+
+// duration returns the interval length in minutes, or nil while the issue is still in the status
+func (i statusInterval) duration() *float64 {
+	if i.exitedAt == nil {
+		return nil
+	}
+	minutes := i.exitedAt.Sub(i.enteredAt).Minutes()
+	return &minutes
+}
+
+// businessDuration returns the working time of the interval in minutes, or nil while the issue is still in the status
+func (i statusInterval) businessDuration(cal *calendar.Calendar) *float64 {
+	if i.exitedAt == nil {
+		return nil
+	}
+	minutes := cal.WorkingMinutes(i.enteredAt, *i.exitedAt)
+	return &minutes
+}
+
+// UpdateStatusIntervals rebuilds the status_intervals rows of the given issues
+// from the stored status field changelog. Business minutes are counted in the
+// working time of the calendar. It returns the earliest time at which the
+// rebuilt intervals differ from the stored ones, or nil when nothing changed.
+func (s *Service) UpdateStatusIntervals(ctx context.Context, issueKeys []string, cal *calendar.Calendar) (*time.Time, error) {
+	ctx, span := tracing.Start(ctx, "db.update_status_intervals", attribute.Int("db.issues", len(issueKeys)))
+	defer span.End()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	keys := nonNilStrings(issueKeys)
+
+	issues, changes, statusTypes, err := loadStatusHistory(ctx, tx, s.orgID, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := loadStatusIntervals(ctx, tx, s.orgID, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM status_intervals WHERE organization_id = $2 AND issue_key = ANY($1)`, keys, s.orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear status intervals: %w", err)
+	}
+
+	var changedFrom *time.Time
+	for key, intervals := range previous {
+		if _, ok := issues[key]; !ok {
+			changedFrom = earliestTime(changedFrom, firstIntervalChange(intervals, nil))
+		}
+	}
+
+	total := 0
+	for key, issue := range issues {
+		intervals := buildStatusIntervals(key, issue, changes[key], statusTypes)
+		changedFrom = earliestTime(changedFrom, firstIntervalChange(previous[key], intervals))
+		for _, interval := range intervals {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO status_intervals (
+					organization_id, issue_key, position, status_key, status_display, status_type,
+					entered_at, exited_at, duration_minutes, business_minutes
+				) VALUES (
+					$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+				)
+			`, s.orgID, interval.issueKey, interval.position, interval.status.key, interval.status.display,
+				nullString(interval.statusType), interval.enteredAt, interval.exitedAt, interval.duration(),
+				interval.businessDuration(cal))
+			if err != nil {
+				return nil, fmt.Errorf("failed to insert status interval of issue %s: %w", key, err)
+			}
+			total++
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	metrics.RowsUpserted.WithLabelValues("status_intervals").Add(float64(total))
+
+	slog.Info("Successfully updated status intervals", "issues", len(issues), "rows", total)
+	return changedFrom, nil
+}
+
+// loadStatusIntervals reads the stored status intervals of the given issues of
+// the organization ordered by position
+func loadStatusIntervals(ctx context.Context, tx pgx.Tx, orgID string, keys []string) (map[string][]statusInterval, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT issue_key, position, status_key, COALESCE(status_display, ''), COALESCE(status_type, ''),
+			entered_at, exited_at
+		FROM status_intervals
+		WHERE organization_id = $2 AND issue_key = ANY($1)
+		ORDER BY issue_key, position
+	`, keys, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status intervals: %w", err)
+	}
+	defer rows.Close()
+
+	intervals := make(map[string][]statusInterval)
+	for rows.Next() {
+		var i statusInterval
+		if err := rows.Scan(&i.issueKey, &i.position, &i.status.key, &i.status.display, &i.statusType,
+			&i.enteredAt, &i.exitedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan status interval: %w", err)
+		}
+		intervals[i.issueKey] = append(intervals[i.issueKey], i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate status intervals: %w", err)
+	}
+	return intervals, nil
+}
+
+// firstIntervalChange returns the earliest time from which the status
+// intervals of an issue differ, or nil when they are the same. An interval
+// that only got a different exit time changes the issue from that exit on.
+func firstIntervalChange(previous, current []statusInterval) *time.Time {
+	for i := 0; i < len(previous) || i < len(current); i++ {
+		switch {
+		case i >= len(previous):
+			return &current[i].enteredAt
+		case i >= len(current):
+			return &previous[i].enteredAt
+		}
+
+		p, c := previous[i], current[i]
+		if p.status.key != c.status.key || p.statusType != c.statusType || !p.enteredAt.Equal(c.enteredAt) {
+			return earliestTime(&p.enteredAt, &c.enteredAt)
+		}
+		if !sameTime(p.exitedAt, c.exitedAt) {
+			if p.exitedAt == nil {
+				return c.exitedAt
+			}
+			return earliestTime(p.exitedAt, c.exitedAt)
+		}
+	}
+	return nil
+}
+
+// earliestTime returns the earlier of two optional times
+func earliestTime(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.Before(*a)) {
+		return b
+	}
+	return a
+}
+
+// sameTime reports whether two optional times are equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// loadStatusHistory reads the creation time and current status of the given
+// issues of the organization, their status changes in chronological order and
+// the status type of every status key
+func loadStatusHistory(ctx context.Context, tx pgx.Tx, orgID string, keys []string) (map[string]issueStatus, map[string][]statusChange, map[string]string, error) {
+	issues := make(map[string]issueStatus)
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT ON (key) key, created_at, COALESCE(status_key, ''), COALESCE(status_display, '')
+		FROM issues
+		WHERE organization_id = $2 AND key = ANY($1) AND created_at IS NOT NULL
+		ORDER BY key, updated_at DESC
+	`, keys, orgID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to query issue statuses: %w", err)
+	}
+	for rows.Next() {
+		var key string
+		var issue issueStatus
+		if err := rows.Scan(&key, &issue.createdAt, &issue.status.key, &issue.status.display); err != nil {
+			rows.Close()
+			return nil, nil, nil, fmt.Errorf("failed to scan issue status: %w", err)
+		}
+		issues[key] = issue
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to iterate issue statuses: %w", err)
+	}
+
+	changes := make(map[string][]statusChange)
+	rows, err = tx.Query(ctx, `
+		SELECT issue_key, updated_at, COALESCE(from_key, ''), COALESCE(from_display, ''),
+			COALESCE(to_key, ''), COALESCE(to_display, '')
+		FROM changelog
+		WHERE organization_id = $3 AND issue_key = ANY($1) AND field_id = $2
+		ORDER BY issue_key, updated_at, tracker_id
+	`, keys, statusFieldID, orgID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to query status changes: %w", err)
+	}
+	for rows.Next() {
+		var key string
+		var c statusChange
+		if err := rows.Scan(&key, &c.at, &c.from.key, &c.from.display, &c.to.key, &c.to.display); err != nil {
+			rows.Close()
+			return nil, nil, nil, fmt.Errorf("failed to scan status change: %w", err)
+		}
+		changes[key] = append(changes[key], c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to iterate status changes: %w", err)
+	}
+
+	statusTypes := make(map[string]string)
+	rows, err = tx.Query(ctx, `SELECT status_key, status_type FROM status_types WHERE organization_id = $1`, orgID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to query status types: %w", err)
+	}
+	for rows.Next() {
+		var key, statusType string
+		if err := rows.Scan(&key, &statusType); err != nil {
+			rows.Close()
+			return nil, nil, nil, fmt.Errorf("failed to scan status type: %w", err)
+		}
+		statusTypes[key] = statusType
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to iterate status types: %w", err)
+	}
+
+	return issues, changes, statusTypes, nil
+}
+
+// buildStatusIntervals replays the status changes of an issue. The status
+// before the first change is counted from the issue creation; without changes
+// the issue has been in its current status since creation.
+func buildStatusIntervals(issueKey string, issue issueStatus, changes []statusChange, statusTypes map[string]string) []statusInterval {
+	var result []statusInterval
+
+	current := issue.status
+	entered := issue.createdAt
+	if len(changes) > 0 {
+		current = changes[0].from
+	}
+
+	for _, change := range changes {
+		if current.key != "" {
+			exited := change.at
+			result = append(result, statusInterval{
+				issueKey:   issueKey,
+				position:   len(result),
+				status:     current,
+				statusType: statusTypes[current.key],
+				enteredAt:  entered,
+				exitedAt:   &exited,
+			})
+		}
+		current = change.to
+		entered = change.at
+	}
+
+	if current.key != "" {
+		result = append(result, statusInterval{
+			issueKey:   issueKey,
+			position:   len(result),
+			status:     current,
+			statusType: statusTypes[current.key],
+			enteredAt:  entered,
+		})
+	}
+	return result
+}
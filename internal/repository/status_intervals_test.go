@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func at(day, hour int) time.Time {
+	return time.Date(2024, 3, day, hour, 0, 0, 0, time.UTC)
+}
+
+func atPtr(day, hour int) *time.Time {
+	t := at(day, hour)
+	return &t
+}
+
+func TestBuildStatusIntervals(t *testing.T) {
+	open := statusRef{key: "open", display: "Open"}
+	inProgress := statusRef{key: "inProgress", display: "In progress"}
+	closed := statusRef{key: "closed", display: "Closed"}
+	statusTypes := map[string]string{"open": "new", "inProgress": "inProgress", "closed": "done"}
+
+	tests := []struct {
+		name    string
+		issue   issueStatus
+		changes []statusChange
+		want    []statusInterval
+	}{
+		{
+			name:  "no changes",
+			issue: issueStatus{createdAt: at(1, 10), status: open},
+			want: []statusInterval{
+				{issueKey: "TEST-1", position: 0, status: open, statusType: "new", enteredAt: at(1, 10)},
+			},
+		},
+		{
+			name:  "closed issue",
+			issue: issueStatus{createdAt: at(1, 10), status: closed},
+			changes: []statusChange{
+				{at: at(2, 10), from: open, to: inProgress},
+				{at: at(4, 12), from: inProgress, to: closed},
+			},
+			want: []statusInterval{
+				{issueKey: "TEST-1", position: 0, status: open, statusType: "new", enteredAt: at(1, 10), exitedAt: atPtr(2, 10)},
+				{issueKey: "TEST-1", position: 1, status: inProgress, statusType: "inProgress", enteredAt: at(2, 10), exitedAt: atPtr(4, 12)},
+				{issueKey: "TEST-1", position: 2, status: closed, statusType: "done", enteredAt: at(4, 12)},
+			},
+		},
+		{
+			name:  "reopened issue",
+			issue: issueStatus{createdAt: at(1, 10), status: inProgress},
+			changes: []statusChange{
+				{at: at(2, 10), from: open, to: closed},
+				{at: at(3, 10), from: closed, to: inProgress},
+			},
+			want: []statusInterval{
+				{issueKey: "TEST-1", position: 0, status: open, statusType: "new", enteredAt: at(1, 10), exitedAt: atPtr(2, 10)},
+				{issueKey: "TEST-1", position: 1, status: closed, statusType: "done", enteredAt: at(2, 10), exitedAt: atPtr(3, 10)},
+				{issueKey: "TEST-1", position: 2, status: inProgress, statusType: "inProgress", enteredAt: at(3, 10)},
+			},
+		},
+		{
+			name:  "first change without a previous status",
+			issue: issueStatus{createdAt: at(1, 10), status: inProgress},
+			changes: []statusChange{
+				{at: at(1, 10), to: open},
+				{at: at(2, 10), from: open, to: inProgress},
+			},
+			want: []statusInterval{
+				{issueKey: "TEST-1", position: 0, status: open, statusType: "new", enteredAt: at(1, 10), exitedAt: atPtr(2, 10)},
+				{issueKey: "TEST-1", position: 1, status: inProgress, statusType: "inProgress", enteredAt: at(2, 10)},
+			},
+		},
+		{
+			name:  "unknown status type",
+			issue: issueStatus{createdAt: at(1, 10), status: statusRef{key: "review", display: "Review"}},
+			want: []statusInterval{
+				{issueKey: "TEST-1", position: 0, status: statusRef{key: "review", display: "Review"}, enteredAt: at(1, 10)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildStatusIntervals("TEST-1", tt.issue, tt.changes, statusTypes)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildStatusIntervals() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstIntervalChange(t *testing.T) {
+	open := statusRef{key: "open", display: "Open"}
+	closed := statusRef{key: "closed", display: "Closed"}
+
+	intervals := []statusInterval{
+		{position: 0, status: open, statusType: "new", enteredAt: at(1, 10), exitedAt: atPtr(3, 10)},
+		{position: 1, status: closed, statusType: "done", enteredAt: at(3, 10)},
+	}
+
+	tests := []struct {
+		name     string
+		previous []statusInterval
+		current  []statusInterval
+		want     *time.Time
+	}{
+		{
+			name:     "unchanged",
+			previous: intervals,
+			current:  intervals,
+			want:     nil,
+		},
+		{
+			name:    "new issue",
+			current: intervals,
+			want:    atPtr(1, 10),
+		},
+		{
+			name:     "removed intervals",
+			previous: intervals,
+			want:     atPtr(1, 10),
+		},
+		{
+			name:     "issue reopened",
+			previous: intervals,
+			current: []statusInterval{
+				intervals[0],
+				{position: 1, status: closed, statusType: "done", enteredAt: at(3, 10), exitedAt: atPtr(5, 10)},
+				{position: 2, status: open, statusType: "new", enteredAt: at(5, 10)},
+			},
+			want: atPtr(5, 10),
+		},
+		{
+			name: "open interval closed",
+			previous: []statusInterval{
+				{position: 0, status: open, statusType: "new", enteredAt: at(1, 10)},
+			},
+			current: intervals,
+			want:    atPtr(3, 10),
+		},
+		{
+			name:     "backdated exit",
+			previous: intervals,
+			current: []statusInterval{
+				{position: 0, status: open, statusType: "new", enteredAt: at(1, 10), exitedAt: atPtr(2, 10)},
+				{position: 1, status: closed, statusType: "done", enteredAt: at(2, 10)},
+			},
+			want: atPtr(2, 10),
+		},
+		{
+			name:     "status type changed",
+			previous: intervals,
+			current: []statusInterval{
+				intervals[0],
+				{position: 1, status: closed, statusType: "cancelled", enteredAt: at(3, 10)},
+			},
+			want: atPtr(3, 10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := firstIntervalChange(tt.previous, tt.current)
+			if !sameTime(got, tt.want) {
+				t.Errorf("firstIntervalChange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
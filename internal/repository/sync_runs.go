@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/domain"
+)
+
+// StartSyncRun records the beginning of a sync run
+func (s *Service) StartSyncRun(ctx context.Context, report *domain.RunReport) error {
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO sync_runs (
+			run_id, organization_id, started_at, mode, filter, status
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+	`,
+		report.RunID,
+		report.OrganizationID,
+		report.StartedAt,
+		report.Mode,
+		report.Filter,
+		report.Status,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert sync run %s: %w", report.RunID, err)
+	}
+	return nil
+}
+
+// FinishSyncRun stores the final state and counters of a sync run
+func (s *Service) FinishSyncRun(ctx context.Context, report *domain.RunReport) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE sync_runs SET
+			finished_at = $2,
+			status = $3,
+			issues_fetched = $4,
+			issues_inserted = $5,
+			issues_updated = $6,
+			issues_deleted = $7,
+			issues_out_of_scope = $8,
+			changelogs = $9,
+			errors = $10,
+			api_calls = $11,
+			error_message = $12,
+			updated_at_db = CURRENT_TIMESTAMP
+		WHERE run_id = $1
+	`,
+		report.RunID,
+		report.FinishedAt,
+		report.Status,
+		report.IssuesFetched,
+		report.IssuesInserted,
+		report.IssuesUpdated,
+		report.IssuesDeleted,
+		report.IssuesOutOfScope,
+		report.Changelogs,
+		report.Errors,
+		report.APICalls,
+		func() interface{} {
+			if report.Error == "" {
+				return nil
+			}
+			return report.Error
+		}(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update sync run %s: %w", report.RunID, err)
+	}
+	return nil
+}
+
+// GetLastSuccessfulSyncTime returns the finish time of the last successful sync run
+func (s *Service) GetLastSuccessfulSyncTime(ctx context.Context) (*time.Time, error) {
+	var finishedAt time.Time
+	err := s.db.QueryRow(ctx, `
+		SELECT finished_at
+		FROM sync_runs
+		WHERE status = $1
+		ORDER BY finished_at DESC
+		LIMIT 1
+	`, domain.RunStatusSuccess).Scan(&finishedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get last successful sync time: %w", err)
+	}
+	return &finishedAt, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracker"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SaveUsers saves organization users to the database
+func (s *Service) SaveUsers(ctx context.Context, users []tracker.UserInfo) error {
+	slog.Info("Starting save users", "total_users", len(users))
+
+	ctx, span := tracing.Start(ctx, "db.save_users", attribute.Int("db.rows", len(users)))
+	defer span.End()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Prepare the insert statement
+	query := `
+		INSERT INTO users (
+			organization_id, uid, tracker_uid, login, email, display,
+			first_name, last_name, external, dismissed, cloud_uid, passport_uid
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		) ON CONFLICT (organization_id, uid) DO UPDATE SET
+			tracker_uid = EXCLUDED.tracker_uid,
+			login = EXCLUDED.login,
+			email = EXCLUDED.email,
+			display = EXCLUDED.display,
+			first_name = EXCLUDED.first_name,
+			last_name = EXCLUDED.last_name,
+			external = EXCLUDED.external,
+			dismissed = EXCLUDED.dismissed,
+			cloud_uid = EXCLUDED.cloud_uid,
+			passport_uid = EXCLUDED.passport_uid,
+			updated_at_db = CURRENT_TIMESTAMP
+	`
+
+	_, err = tx.Prepare(ctx, "insert_user", query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert statement: %w", err)
+	}
+
+	// Insert each user
+	for _, u := range users {
+		params := []interface{}{
+			u.OrganizationID,
+			u.ID,
+			func() interface{} {
+				if u.TrackerUID == 0 {
+					return nil
+				}
+				return strconv.FormatInt(u.TrackerUID, 10)
+			}(),
+			u.Login,
+			u.Email,
+			u.Display,
+			u.FirstName,
+			u.LastName,
+			u.External,
+			u.Dismissed,
+			u.CloudUID,
+			func() interface{} {
+				if u.PassportUID == 0 {
+					return nil
+				}
+				return u.PassportUID
+			}(),
+		}
+
+		_, err = tx.Exec(ctx, "insert_user", params...)
+		if err != nil {
+			return fmt.Errorf("failed to insert user %s: %w", u.Login, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	metrics.RowsUpserted.WithLabelValues("users").Add(float64(len(users)))
+
+	slog.Info("Successfully saved all users", "total_users", len(users))
+	return nil
+}
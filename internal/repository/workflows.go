@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/domain"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracker"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SaveWorkflows replaces the stored workflows of the organization, their statuses and transitions
+func (s *Service) SaveWorkflows(ctx context.Context, workflows []tracker.Workflow) error {
+	ctx, span := tracing.Start(ctx, "db.save_workflows", attribute.Int("db.rows", len(workflows)))
+	defer span.End()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Clear the workflows of the organization with their statuses and transitions
+	for _, table := range []string{"workflow_transitions", "workflow_statuses", "workflows"} {
+		_, err := tx.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE organization_id = $1`, table), s.orgID)
+		if err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+
+	transitions := 0
+	for _, wf := range workflows {
+		issueTypes := make([]string, 0, len(wf.IssueTypes))
+		for _, t := range wf.IssueTypes {
+			issueTypes = append(issueTypes, t.Key)
+		}
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO workflows (
+				organization_id, queue_key, workflow_id, name, initial_status_key, issue_types
+			) VALUES (
+				$1, $2, $3, $4, $5, $6
+			) ON CONFLICT (organization_id, queue_key, workflow_id) DO NOTHING
+		`, wf.OrganizationID, wf.QueueKey, wf.ID, wf.Name, wf.InitialStatus.Key, strings.Join(issueTypes, ", "))
+		if err != nil {
+			return fmt.Errorf("failed to insert workflow %s of queue %s: %w", wf.ID, wf.QueueKey, err)
+		}
+
+		// Statuses are stored in step order, followed by transition targets without a step
+		position := 0
+		addStatus := func(status tracker.Field) error {
+			tag, err := tx.Exec(ctx, `
+				INSERT INTO workflow_statuses (
+					organization_id, queue_key, workflow_id, status_key, status_display, position
+				) VALUES (
+					$1, $2, $3, $4, $5, $6
+				) ON CONFLICT DO NOTHING
+			`, wf.OrganizationID, wf.QueueKey, wf.ID, status.Key, status.Display, position)
+			if err != nil {
+				return fmt.Errorf("failed to insert status %s of workflow %s: %w", status.Key, wf.ID, err)
+			}
+			if tag.RowsAffected() > 0 {
+				position++
+			}
+			return nil
+		}
+		for _, step := range wf.Steps {
+			if err := addStatus(step.Status); err != nil {
+				return err
+			}
+		}
+		for _, step := range wf.Steps {
+			for _, tr := range step.Transitions {
+				if err := addStatus(tr.To); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `
+					INSERT INTO workflow_transitions (
+						organization_id, queue_key, workflow_id, from_status_key, transition_id, display,
+						to_status_key
+					) VALUES (
+						$1, $2, $3, $4, $5, $6, $7
+					) ON CONFLICT DO NOTHING
+				`, wf.OrganizationID, wf.QueueKey, wf.ID, step.Status.Key, tr.ID, tr.Display, tr.To.Key)
+				if err != nil {
+					return fmt.Errorf("failed to insert transition %s of workflow %s: %w", tr.ID, wf.ID, err)
+				}
+				transitions++
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	metrics.RowsUpserted.WithLabelValues("workflows").Add(float64(len(workflows)))
+	metrics.RowsUpserted.WithLabelValues("workflow_transitions").Add(float64(transitions))
+
+	slog.Info("Successfully saved workflows", "workflows", len(workflows), "transitions", transitions)
+	return nil
+}
+
+// GetWorkflows returns the stored workflows of a queue of the organization
+func (s *Service) GetWorkflows(ctx context.Context, queueKey string) ([]domain.Workflow, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT workflow_id, COALESCE(name, ''), COALESCE(initial_status_key, '')
+		FROM workflows
+		WHERE organization_id = $2 AND queue_key = $1
+		ORDER BY workflow_id
+	`, queueKey, s.orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workflows: %w", err)
+	}
+	var workflows []domain.Workflow
+	index := make(map[string]int)
+	for rows.Next() {
+		wf := domain.Workflow{QueueKey: queueKey}
+		if err := rows.Scan(&wf.ID, &wf.Name, &wf.InitialStatus); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan workflow: %w", err)
+		}
+		index[wf.ID] = len(workflows)
+		workflows = append(workflows, wf)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate workflows: %w", err)
+	}
+
+	rows, err = s.db.Query(ctx, `
+		SELECT workflow_id, status_key, COALESCE(status_display, '')
+		FROM workflow_statuses
+		WHERE organization_id = $2 AND queue_key = $1
+		ORDER BY workflow_id, position
+	`, queueKey, s.orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workflow statuses: %w", err)
+	}
+	for rows.Next() {
+		var workflowID string
+		var st domain.WorkflowStatus
+		if err := rows.Scan(&workflowID, &st.Key, &st.Display); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan workflow status: %w", err)
+		}
+		if i, ok := index[workflowID]; ok {
+			workflows[i].Statuses = append(workflows[i].Statuses, st)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate workflow statuses: %w", err)
+	}
+
+	rows, err = s.db.Query(ctx, `
+		SELECT workflow_id, transition_id, COALESCE(display, ''), from_status_key, to_status_key
+		FROM workflow_transitions
+		WHERE organization_id = $2 AND queue_key = $1
+		ORDER BY workflow_id, from_status_key, transition_id
+	`, queueKey, s.orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workflow transitions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var workflowID string
+		var tr domain.WorkflowTransition
+		if err := rows.Scan(&workflowID, &tr.ID, &tr.Display, &tr.From, &tr.To); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow transition: %w", err)
+		}
+		if i, ok := index[workflowID]; ok {
+			workflows[i].Transitions = append(workflows[i].Transitions, tr)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate workflow transitions: %w", err)
+	}
+
+	return workflows, nil
+}
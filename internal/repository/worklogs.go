@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracker"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SaveWorklogs upserts worklogs and removes the worklogs of the given issues
+// that are no longer returned by Tracker
+func (s *Service) SaveWorklogs(ctx context.Context, issueKeys []string, worklogs []tracker.Worklog) error {
+	ctx, span := tracing.Start(ctx, "db.save_worklogs", attribute.Int("db.rows", len(worklogs)))
+	defer span.End()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Prepare the insert statement
+	_, err = tx.Prepare(ctx, "insert_worklog", `
+		INSERT INTO worklogs (
+			organization_id, tracker_id, issue_key, author_id, author_display,
+			updated_by_id, updated_by_display, comment, start, duration,
+			created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		) ON CONFLICT (organization_id, tracker_id) DO UPDATE SET
+			issue_key = EXCLUDED.issue_key,
+			author_id = EXCLUDED.author_id,
+			author_display = EXCLUDED.author_display,
+			updated_by_id = EXCLUDED.updated_by_id,
+			updated_by_display = EXCLUDED.updated_by_display,
+			comment = EXCLUDED.comment,
+			start = EXCLUDED.start,
+			duration = EXCLUDED.duration,
+			created_at = EXCLUDED.created_at,
+			updated_at = EXCLUDED.updated_at,
+			updated_at_db = CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert statement: %w", err)
+	}
+
+	ids := make([]string, 0, len(worklogs))
+	for _, w := range worklogs {
+		_, err = tx.Exec(ctx, "insert_worklog",
+			w.OrganizationID,
+			w.ID.String(),
+			w.IssueKey,
+			w.CreatedBy.ID,
+			w.CreatedBy.Display,
+			w.UpdatedBy.ID,
+			w.UpdatedBy.Display,
+			w.Comment,
+			nullTime(w.Start),
+			w.Duration,
+			nullTime(w.CreatedAt),
+			nullTime(w.UpdatedAt),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert worklog %s of issue %s: %w", w.ID, w.IssueKey, err)
+		}
+		ids = append(ids, w.ID.String())
+	}
+
+	// Remove worklogs deleted from the synced issues
+	_, err = tx.Exec(ctx, `
+		DELETE FROM worklogs
+		WHERE organization_id = $3 AND issue_key = ANY($1) AND NOT (tracker_id = ANY($2))
+	`, nonNilStrings(issueKeys), nonNilStrings(ids), s.orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete removed worklogs: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	metrics.RowsUpserted.WithLabelValues("worklogs").Add(float64(len(worklogs)))
+
+	slog.Info("Successfully saved all worklogs", "total_worklogs", len(worklogs))
+	return nil
+}
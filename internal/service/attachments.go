@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/domain"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/blobstore"
+)
+
+// downloadAttachments stores the content of the not yet downloaded attachments
+// of the given issues. Objects are addressed by the SHA-256 of their content,
+// so the same file attached to several issues is stored once. An attachment
+// that fails is logged, counted in the report and retried by the next sync.
+func (s *Service) downloadAttachments(ctx context.Context, issueKeys []string, report *domain.RunReport) error {
+	store, err := s.newBlobStore()
+	if err != nil {
+		return err
+	}
+
+	refs, err := s.storage.GetPendingAttachments(ctx, issueKeys)
+	if err != nil {
+		return err
+	}
+
+	var stored, failed int
+	for _, ref := range refs {
+		uploaded, err := s.downloadAttachment(ctx, store, ref)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Warn("Failed to store attachment, skipping", "attachment_id", ref.TrackerID, "issue_key", ref.IssueKey, "error", err)
+			report.Errors++
+			failed++
+			continue
+		}
+		if uploaded {
+			stored++
+		}
+	}
+
+	slog.Info("Finished downloading attachments",
+		"total_attachments", len(refs),
+		"stored_objects", stored,
+		"failed", failed)
+	return nil
+}
+
+// downloadAttachment downloads an attachment to a temporary file, uploads it
+// to the store unless an object with the same content exists and records the
+// content hash. It reports whether a new object was stored.
+func (s *Service) downloadAttachment(ctx context.Context, store blobstore.Store, ref domain.AttachmentRef) (bool, error) {
+	tmp, err := os.CreateTemp("", "tracker-attachment-*")
+	if err != nil {
+		return false, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	if err := s.tracker.DownloadAttachment(ctx, ref.ContentURL, io.MultiWriter(tmp, hash)); err != nil {
+		return false, fmt.Errorf("failed to download attachment %s of issue %s: %w", ref.TrackerID, ref.IssueKey, err)
+	}
+	contentSHA256 := hex.EncodeToString(hash.Sum(nil))
+	key := blobstore.ContentKey(contentSHA256)
+
+	exists, err := store.Exists(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		size, err := tmp.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return false, fmt.Errorf("failed to get size of attachment %s: %w", ref.TrackerID, err)
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return false, fmt.Errorf("failed to rewind attachment %s: %w", ref.TrackerID, err)
+		}
+		if err := store.Put(ctx, key, tmp, size, contentSHA256, ref.Mimetype); err != nil {
+			return false, err
+		}
+	}
+
+	if err := s.storage.SetAttachmentContent(ctx, ref.TrackerID, contentSHA256, key); err != nil {
+		return false, err
+	}
+	return !exists, nil
+}
+
+// newBlobStore creates the attachment store selected by ATTACHMENTS_DOWNLOAD
+func (s *Service) newBlobStore() (blobstore.Store, error) {
+	cfg := s.cfg.Attachments
+	switch cfg.Download {
+	case "dir":
+		return blobstore.NewDirStore(cfg.Dir)
+	case "s3":
+		return blobstore.NewS3Store(blobstore.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			Prefix:          cfg.S3Prefix,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported attachment download mode %q", cfg.Download)
+	}
+}
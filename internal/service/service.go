@@ -2,49 +2,213 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/nemirlev/yc-tracker-go-data-import/internal/config"
 	"github.com/nemirlev/yc-tracker-go-data-import/internal/domain"
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/notifier"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/calendar"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
 	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracker"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/time/rate"
 )
 
 type Service struct {
-	cfg     *config.Config
-	tracker *tracker.Service
-	storage domain.Repository
-	workers int
+	cfg      *config.Config
+	tracker  *tracker.Service
+	storage  domain.Repository
+	notifier *notifier.Notifier
+	calendar *calendar.Calendar
+	workers  int
 }
 
-func NewService(cfg *config.Config, storage domain.Repository) *Service {
+func NewService(cfg *config.Config, storage domain.Repository, cal *calendar.Calendar) *Service {
 	return &Service{
-		cfg:     cfg,
-		tracker: tracker.NewService(cfg),
-		storage: storage,
-		workers: 5, // Number of concurrent workers for processing issues
+		cfg:      cfg,
+		tracker:  tracker.NewService(cfg),
+		storage:  storage,
+		notifier: notifier.New(cfg),
+		calendar: cal,
+		workers:  5, // Number of concurrent workers for processing issues
 	}
 }
 
-// Sync synchronizes issues and their changelogs from Tracker to the database
-func (s *Service) Sync(ctx context.Context) error {
+// Sync synchronizes issues and their changelogs from Tracker to the database.
+// The returned report is non-nil even when the sync fails.
+func (s *Service) Sync(ctx context.Context) (*domain.RunReport, error) {
+	report := &domain.RunReport{
+		RunID:          newRunID(),
+		OrganizationID: s.cfg.Tracker.OrgID,
+		StartedAt:      time.Now().UTC(),
+		Mode:           s.runMode(),
+		Filter:         s.cfg.Tracker.Filter,
+		Status:         domain.RunStatusRunning,
+	}
+	apiCallsBefore := s.tracker.APICalls()
+
+	slog.Info("Starting sync run", "run_id", report.RunID, "mode", report.Mode)
+
+	if err := s.storage.StartSyncRun(ctx, report); err != nil {
+		report.FinishedAt = time.Now().UTC()
+		report.Duration = report.FinishedAt.Sub(report.StartedAt)
+		report.DurationSeconds = report.Duration.Seconds()
+		report.Status = domain.RunStatusFailed
+		report.Errors++
+		report.Error = err.Error()
+		metrics.ObserveSync(report.Status, report.Duration, report.FinishedAt, false)
+		s.notifier.Notify(context.WithoutCancel(ctx), report)
+		return report, fmt.Errorf("failed to record sync run start: %w", err)
+	}
+
+	runCtx, span := tracing.Start(ctx, "sync",
+		attribute.String("sync.run_id", report.RunID),
+		attribute.String("sync.mode", report.Mode),
+		attribute.String("sync.filter", report.Filter),
+	)
+	syncErr := s.sync(runCtx, report)
+	tracing.End(span, syncErr)
+
+	report.FinishedAt = time.Now().UTC()
+	report.Duration = report.FinishedAt.Sub(report.StartedAt)
+	report.DurationSeconds = report.Duration.Seconds()
+	report.APICalls = s.tracker.APICalls() - apiCallsBefore
+	report.Status = domain.RunStatusSuccess
+	if syncErr != nil {
+		report.Status = domain.RunStatusFailed
+		report.Errors++
+		report.Error = syncErr.Error()
+	}
+
+	metrics.ObserveSync(report.Status, report.Duration, report.FinishedAt, syncErr == nil)
+
+	// Record the outcome even if the sync was cancelled
+	if err := s.storage.FinishSyncRun(context.WithoutCancel(ctx), report); err != nil {
+		slog.Error("Failed to record sync run result", "run_id", report.RunID, "error", err)
+	}
+
+	slog.Info("Finished sync run",
+		"run_id", report.RunID,
+		"status", report.Status,
+		"duration", report.Duration.String(),
+		"api_calls", report.APICalls)
+
+	s.notifier.Notify(context.WithoutCancel(ctx), report)
+
+	return report, syncErr
+}
+
+// sync performs the synchronization steps and fills the report counters.
+// Each step is traced as a child span of the run span in ctx.
+func (s *Service) sync(ctx context.Context, report *domain.RunReport) error {
+	// Refresh queues, projects, components and versions first
+	phaseCtx, span := tracing.Start(ctx, "sync.fetch_references")
+	refs, err := s.tracker.GetReferences(phaseCtx)
+	tracing.End(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to get reference data from tracker: %w", err)
+	}
+
+	phaseCtx, span = tracing.Start(ctx, "sync.save_references")
+	err = s.storage.SaveReferences(phaseCtx, refs)
+	tracing.End(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to save reference data to database: %w", err)
+	}
+
+	// Refresh the workflows of all queues
+	phaseCtx, span = tracing.Start(ctx, "sync.fetch_workflows")
+	workflows, err := s.tracker.GetWorkflows(phaseCtx, refs.Queues)
+	tracing.End(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to get workflows from tracker: %w", err)
+	}
+
+	phaseCtx, span = tracing.Start(ctx, "sync.save_workflows")
+	err = s.storage.SaveWorkflows(phaseCtx, workflows)
+	tracing.End(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to save workflows to database: %w", err)
+	}
+
+	// Refresh agile boards and their sprints
+	phaseCtx, span = tracing.Start(ctx, "sync.fetch_boards")
+	boards, err := s.tracker.GetBoards(phaseCtx)
+	var sprints []tracker.Sprint
+	if err == nil {
+		sprints, err = s.tracker.GetSprints(phaseCtx, boards)
+	}
+	tracing.End(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to get boards and sprints from tracker: %w", err)
+	}
+
+	phaseCtx, span = tracing.Start(ctx, "sync.save_boards")
+	err = s.storage.SaveBoards(phaseCtx, boards, sprints)
+	tracing.End(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to save boards and sprints to database: %w", err)
+	}
+
+	// Refresh projects, portfolios and goals. A failed fetch keeps the stored
+	// entities and does not fail the sync.
+	phaseCtx, span = tracing.Start(ctx, "sync.fetch_entities")
+	entities, err := s.tracker.GetEntities(phaseCtx)
+	tracing.End(span, err)
+	if err != nil {
+		slog.Warn("Failed to get entities from tracker, keeping stored entities", "error", err)
+		report.Errors++
+	} else {
+		phaseCtx, span = tracing.Start(ctx, "sync.save_entities")
+		err = s.storage.SaveEntities(phaseCtx, entities)
+		tracing.End(span, err)
+		if err != nil {
+			return fmt.Errorf("failed to save entities to database: %w", err)
+		}
+	}
+
 	// Get all issues from Tracker
-	issues, err := s.tracker.GetIssues(ctx, s.cfg.Tracker.Filter)
+	phaseCtx, span = tracing.Start(ctx, "sync.fetch_issues")
+	issues, err := s.tracker.GetIssues(phaseCtx, s.cfg.Tracker.Filter)
+	tracing.End(span, err)
 	if err != nil {
 		return fmt.Errorf("failed to get issues from tracker: %w", err)
 	}
+	report.IssuesFetched = len(issues)
 
 	slog.Info("Retrieved issues from Tracker", "count", len(issues))
 
 	// Save issues to database
-	if err := s.storage.SaveIssues(ctx, issues); err != nil {
+	phaseCtx, span = tracing.Start(ctx, "sync.save_issues")
+	stats, err := s.storage.SaveIssues(phaseCtx, issues)
+	tracing.End(span, err)
+	if err != nil {
 		return fmt.Errorf("failed to save issues to database: %w", err)
 	}
+	report.IssuesInserted = stats.Inserted
+	report.IssuesUpdated = stats.Updated
 
 	slog.Info("Saved issues to database")
 
+	// Mark issues that left the filter or were deleted
+	if s.cfg.Tracker.Reconcile {
+		phaseCtx, span = tracing.Start(ctx, "sync.reconcile_issues")
+		err = s.reconcile(phaseCtx, report)
+		tracing.End(span, err)
+		if err != nil {
+			return fmt.Errorf("failed to reconcile issues: %w", err)
+		}
+	}
+
 	// Get all statuses from Tracker
-	statusTypes, err := s.tracker.GetStatusTypes(ctx)
+	phaseCtx, span = tracing.Start(ctx, "sync.fetch_status_types")
+	statusTypes, err := s.tracker.GetStatusTypes(phaseCtx)
+	tracing.End(span, err)
 	if err != nil {
 		return fmt.Errorf("failed to get status types from tracker: %w", err)
 	}
@@ -52,25 +216,238 @@ func (s *Service) Sync(ctx context.Context) error {
 	slog.Info("Retrieved status types from Tracker", "count", len(statusTypes))
 
 	// Save status types to database
-	if err := s.storage.SaveStatusTypes(ctx, statusTypes); err != nil {
+	phaseCtx, span = tracing.Start(ctx, "sync.save_status_types")
+	err = s.storage.SaveStatusTypes(phaseCtx, statusTypes)
+	tracing.End(span, err)
+	if err != nil {
 		return fmt.Errorf("failed to save status types to database: %w", err)
 	}
 
+	// Get all users from Tracker
+	phaseCtx, span = tracing.Start(ctx, "sync.fetch_users")
+	users, err := s.tracker.GetUsers(phaseCtx)
+	tracing.End(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to get users from tracker: %w", err)
+	}
+
+	// Save users to database
+	phaseCtx, span = tracing.Start(ctx, "sync.save_users")
+	err = s.storage.SaveUsers(phaseCtx, users)
+	tracing.End(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to save users to database: %w", err)
+	}
+
 	// Get changelogs concurrently
-	changelogEntries, err := s.tracker.GetChangelogsConcurrently(ctx, issues)
+	phaseCtx, span = tracing.Start(ctx, "sync.fetch_changelogs")
+	changelogEntries, err := s.tracker.GetChangelogsConcurrently(phaseCtx, issues)
+	tracing.End(span, err)
 	if err != nil {
 		return fmt.Errorf("failed to get changelogs concurrently: %w", err)
 	}
 
 	// Save changelogs to database
-	if err := s.storage.SaveChangelogs(ctx, changelogEntries); err != nil {
+	phaseCtx, span = tracing.Start(ctx, "sync.save_changelogs")
+	err = s.storage.SaveChangelogs(phaseCtx, changelogEntries)
+	tracing.End(span, err)
+	if err != nil {
 		return fmt.Errorf("failed to save changelogs to database: %w", err)
 	}
+	report.Changelogs = len(changelogEntries)
+
+	// Rebuild sprint membership from the sprint field changes
+	issueKeys := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		issueKeys = append(issueKeys, issue.Key)
+	}
+	phaseCtx, span = tracing.Start(ctx, "sync.update_sprint_membership")
+	err = s.storage.UpdateSprintMembership(phaseCtx, issueKeys)
+	tracing.End(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to update sprint membership: %w", err)
+	}
+
+	// Rebuild status intervals from the status field changes
+	phaseCtx, span = tracing.Start(ctx, "sync.update_status_intervals")
+	changedFrom, err := s.storage.UpdateStatusIntervals(phaseCtx, issueKeys, s.calendar)
+	tracing.End(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to update status intervals: %w", err)
+	}
+	// Queue and story point changes recorded in issue history change the
+	// snapshots from the start of the new version
+	if from := stats.HistoryChangedFrom; from != nil && (changedFrom == nil || from.Before(*changedFrom)) {
+		changedFrom = from
+	}
+
+	phaseCtx, span = tracing.Start(ctx, "sync.update_flow_metrics")
+	err = s.storage.UpdateFlowMetrics(phaseCtx, issueKeys, s.calendar)
+	tracing.End(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to update flow metrics: %w", err)
+	}
+
+	phaseCtx, span = tracing.Start(ctx, "sync.update_daily_snapshots")
+	err = s.storage.UpdateDailySnapshots(phaseCtx, changedFrom, s.calendar)
+	tracing.End(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to update daily snapshots: %w", err)
+	}
+
+	// Rebuild sprint scope and burndown from the membership and done statuses
+	phaseCtx, span = tracing.Start(ctx, "sync.update_sprint_scope")
+	err = s.storage.UpdateSprintScope(phaseCtx, s.calendar)
+	tracing.End(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to update sprint scope: %w", err)
+	}
+
+	if s.cfg.Worklogs.Import {
+		phaseCtx, span = tracing.Start(ctx, "sync.fetch_worklogs")
+		worklogs, failed, err := s.tracker.GetWorklogsConcurrently(phaseCtx, issues, s.workers)
+		tracing.End(span, err)
+		if err != nil {
+			return fmt.Errorf("failed to get worklogs concurrently: %w", err)
+		}
+		report.Errors += len(failed)
+
+		// Keep the stored worklogs of the issues that failed, the next sync retries them
+		phaseCtx, span = tracing.Start(ctx, "sync.save_worklogs")
+		err = s.storage.SaveWorklogs(phaseCtx, withoutKeys(issueKeys, failed), worklogs)
+		tracing.End(span, err)
+		if err != nil {
+			return fmt.Errorf("failed to save worklogs to database: %w", err)
+		}
+	}
+
+	if s.cfg.Attachments.Import {
+		phaseCtx, span = tracing.Start(ctx, "sync.fetch_attachments")
+		attachments, err := s.tracker.GetAttachmentsConcurrently(phaseCtx, issues, s.workers)
+		tracing.End(span, err)
+		if err != nil {
+			return fmt.Errorf("failed to get attachments concurrently: %w", err)
+		}
+
+		phaseCtx, span = tracing.Start(ctx, "sync.save_attachments")
+		err = s.storage.SaveAttachments(phaseCtx, issueKeys, attachments)
+		tracing.End(span, err)
+		if err != nil {
+			return fmt.Errorf("failed to save attachments to database: %w", err)
+		}
+	}
+
+	if s.cfg.Attachments.Download != "" {
+		phaseCtx, span = tracing.Start(ctx, "sync.download_attachments")
+		err = s.downloadAttachments(phaseCtx, issueKeys, report)
+		tracing.End(span, err)
+		if err != nil {
+			return fmt.Errorf("failed to download attachments: %w", err)
+		}
+	}
 
 	slog.Info("Successfully synchronized data from Tracker")
 	return nil
 }
 
+// reconcile compares stored issues against the current search result. Issues
+// missing from it are marked as deleted when Tracker no longer returns them and
+// as out of scope when they still exist but no longer match the filter.
+func (s *Service) reconcile(ctx context.Context, report *domain.RunReport) error {
+	refs, err := s.tracker.GetIssueRefs(ctx, s.cfg.Tracker.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to get issue refs from tracker: %w", err)
+	}
+
+	inScope := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		inScope[ref.ID] = true
+	}
+
+	stored, err := s.storage.GetActiveIssueKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get stored issues: %w", err)
+	}
+
+	// Stored issues missing from the search are checked one by one, so keep
+	// them under the same request rate as the other per-issue calls
+	limiter := rate.NewLimiter(rate.Limit(20), 5)
+
+	var deleted, outOfScope []string
+	failed := 0
+	for trackerID, key := range stored {
+		if inScope[trackerID] {
+			continue
+		}
+
+		_, err := s.tracker.GetIssue(ctx, limiter, key)
+		switch {
+		case errors.Is(err, tracker.ErrIssueNotFound):
+			deleted = append(deleted, trackerID)
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			// Leave the issue as is and check it again on the next run
+			slog.Warn("Failed to check issue, skipping", "issue_key", key, "error", err)
+			report.Errors++
+			failed++
+		default:
+			outOfScope = append(outOfScope, trackerID)
+		}
+	}
+
+	if err := s.storage.MarkIssuesDeleted(ctx, deleted, report.StartedAt); err != nil {
+		return err
+	}
+	if err := s.storage.MarkIssuesOutOfScope(ctx, outOfScope, report.StartedAt); err != nil {
+		return err
+	}
+	report.IssuesDeleted = len(deleted)
+	report.IssuesOutOfScope = len(outOfScope)
+
+	slog.Info("Reconciled issues",
+		"in_scope", len(refs),
+		"deleted", len(deleted),
+		"out_of_scope", len(outOfScope),
+		"failed", failed)
+	return nil
+}
+
+// runMode reports whether the sync covers the full history or a recent window
+func (s *Service) runMode() string {
+	if s.cfg.Tracker.InitialHistoryDepth != "" {
+		return domain.RunModeIncremental
+	}
+	return domain.RunModeFull
+}
+
+// withoutKeys returns the keys that are not in exclude
+func withoutKeys(keys, exclude []string) []string {
+	if len(exclude) == 0 {
+		return keys
+	}
+	skip := make(map[string]bool, len(exclude))
+	for _, key := range exclude {
+		skip[key] = true
+	}
+	kept := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !skip[key] {
+			kept = append(kept, key)
+		}
+	}
+	return kept
+}
+
+// newRunID generates a random UUID v4 used to identify a sync run
+func newRunID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
 // GetTracker returns the tracker service
 func (s *Service) GetTracker() *tracker.Service {
 	return s.tracker
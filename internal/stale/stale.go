@@ -0,0 +1,87 @@
+// Package stale renders reports of in-progress issues that have been in their
+// current status for unusually long
+package stale
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/domain"
+)
+
+// Supported output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Report is the list of stale issues
+type Report struct {
+	GeneratedAt string              `json:"generated_at"`
+	QueueKey    string              `json:"queue,omitempty"`
+	Percentile  int                 `json:"percentile"`
+	Issues      []domain.StaleIssue `json:"issues"`
+}
+
+// Render writes the report in the given format
+func Render(w io.Writer, format string, r Report) error {
+	switch format {
+	case FormatText:
+		return renderText(w, r)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	default:
+		return fmt.Errorf("unsupported format %q (expected %q or %q)", format, FormatText, FormatJSON)
+	}
+}
+
+// renderText writes the report as a table
+func renderText(w io.Writer, r Report) error {
+	if len(r.Issues) == 0 {
+		_, err := fmt.Fprintf(w, "No issues exceed the %dth percentile of time in status\n", r.Percentile)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Issue\tStatus\tAssignee\tIn status\tP%d\tSummary\n", r.Percentile)
+	for _, issue := range r.Issues {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", issue.Key, issue.StatusDisplay, issue.AssigneeDisplay,
+			FormatMinutes(issue.AgeMinutes), FormatMinutes(issue.ThresholdMinutes), issue.Summary)
+	}
+	return tw.Flush()
+}
+
+// Message returns the report as a short chat message
+func Message(r Report) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Stale issues (over the %dth percentile of time in status): %d", r.Percentile, len(r.Issues))
+	for _, issue := range r.Issues {
+		fmt.Fprintf(&sb, "\n%s %s: %s in %s (p%d %s)", issue.Key, issue.Summary, FormatMinutes(issue.AgeMinutes),
+			issue.StatusDisplay, r.Percentile, FormatMinutes(issue.ThresholdMinutes))
+		if issue.AssigneeDisplay != "" {
+			fmt.Fprintf(&sb, ", %s", issue.AssigneeDisplay)
+		}
+	}
+	return sb.String()
+}
+
+// FormatMinutes formats a duration in minutes as days and hours, e.g. "3d 4h"
+func FormatMinutes(minutes float64) string {
+	d := time.Duration(minutes * float64(time.Minute)).Round(time.Hour)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return "<1h"
+	}
+}
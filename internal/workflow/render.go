@@ -0,0 +1,132 @@
+// Package workflow renders stored queue workflows as diagrams
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/internal/domain"
+)
+
+// Supported output formats
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+)
+
+// Render writes the workflows in the given format. DOT output contains one
+// digraph per workflow, Mermaid output one state diagram per workflow.
+func Render(w io.Writer, format string, workflows []domain.Workflow) error {
+	for i, wf := range workflows {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		switch format {
+		case FormatDOT:
+			err = renderDOT(w, wf)
+		case FormatMermaid:
+			err = renderMermaid(w, wf)
+		default:
+			return fmt.Errorf("unsupported format %q (expected %q or %q)", format, FormatDOT, FormatMermaid)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to render workflow %s: %w", wf.ID, err)
+		}
+	}
+	return nil
+}
+
+// renderDOT writes a workflow as a Graphviz digraph
+func renderDOT(w io.Writer, wf domain.Workflow) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(title(wf)))
+	b.WriteString("    rankdir=LR;\n")
+	b.WriteString("    node [shape=box, style=rounded];\n")
+	for _, st := range wf.Statuses {
+		fmt.Fprintf(&b, "    %s [label=%s];\n", dotQuote(st.Key), dotQuote(label(st)))
+	}
+	if wf.InitialStatus != "" {
+		b.WriteString("    __start [shape=point];\n")
+		fmt.Fprintf(&b, "    __start -> %s;\n", dotQuote(wf.InitialStatus))
+	}
+	for _, tr := range wf.Transitions {
+		fmt.Fprintf(&b, "    %s -> %s [label=%s];\n", dotQuote(tr.From), dotQuote(tr.To), dotQuote(transitionLabel(tr)))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// renderMermaid writes a workflow as a Mermaid state diagram
+func renderMermaid(w io.Writer, wf domain.Workflow) error {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", mermaidText(title(wf)))
+	b.WriteString("---\n")
+	b.WriteString("stateDiagram-v2\n")
+	for _, st := range wf.Statuses {
+		fmt.Fprintf(&b, "    state \"%s\" as %s\n", mermaidText(label(st)), mermaidID(st.Key))
+	}
+	if wf.InitialStatus != "" {
+		fmt.Fprintf(&b, "    [*] --> %s\n", mermaidID(wf.InitialStatus))
+	}
+	for _, tr := range wf.Transitions {
+		fmt.Fprintf(&b, "    %s --> %s: %s\n", mermaidID(tr.From), mermaidID(tr.To), mermaidText(transitionLabel(tr)))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// title returns the diagram title of a workflow
+func title(wf domain.Workflow) string {
+	if wf.Name == "" {
+		return wf.QueueKey + " " + wf.ID
+	}
+	return wf.QueueKey + " " + wf.Name
+}
+
+// label returns the display name of a status, falling back to its key
+func label(st domain.WorkflowStatus) string {
+	if st.Display == "" {
+		return st.Key
+	}
+	return st.Display
+}
+
+// transitionLabel returns the display name of a transition, falling back to its ID
+func transitionLabel(tr domain.WorkflowTransition) string {
+	if tr.Display == "" {
+		return tr.ID
+	}
+	return tr.Display
+}
+
+// dotQuote quotes a string as a DOT identifier
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(s) + `"`
+}
+
+// mermaidID converts a status key to a Mermaid state identifier
+func mermaidID(key string) string {
+	var b strings.Builder
+	for _, r := range key {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return "s_" + b.String()
+}
+
+// mermaidText removes characters that break Mermaid labels
+func mermaidText(s string) string {
+	return strings.NewReplacer(`"`, "'", "\n", " ", ":", " ", ";", ",").Replace(s)
+}
@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_sync_runs_started_at;
+
+-- Drop sync_runs table
+DROP TABLE IF EXISTS sync_runs;
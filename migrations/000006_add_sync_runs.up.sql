@@ -0,0 +1,24 @@
+-- Create sync_runs table
+CREATE TABLE IF NOT EXISTS sync_runs (
+    id SERIAL PRIMARY KEY,
+    run_id VARCHAR(255) NOT NULL,
+    organization_id VARCHAR(255) NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    mode VARCHAR(255) NOT NULL,
+    filter TEXT,
+    status VARCHAR(255) NOT NULL,
+    issues_fetched INTEGER NOT NULL DEFAULT 0,
+    issues_inserted INTEGER NOT NULL DEFAULT 0,
+    issues_updated INTEGER NOT NULL DEFAULT 0,
+    changelogs INTEGER NOT NULL DEFAULT 0,
+    errors INTEGER NOT NULL DEFAULT 0,
+    api_calls INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT sync_runs_run_id_key UNIQUE (run_id)
+);
+
+-- Create index for sync_runs
+CREATE INDEX IF NOT EXISTS idx_sync_runs_started_at ON sync_runs(started_at);
@@ -0,0 +1,19 @@
+-- Drop views depending on the reconciliation columns
+DROP VIEW IF EXISTS v_issues_all;
+DROP VIEW IF EXISTS v_issues;
+
+-- Remove reconciliation counters from sync_runs table
+ALTER TABLE sync_runs DROP COLUMN issues_out_of_scope;
+ALTER TABLE sync_runs DROP COLUMN issues_deleted;
+
+-- Remove reconciliation columns from issues table
+ALTER TABLE issues DROP COLUMN out_of_scope_at;
+ALTER TABLE issues DROP COLUMN deleted_at;
+
+-- Restore original v_issues view
+CREATE VIEW v_issues AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY tracker_id ORDER BY updated_at DESC) as rn
+    FROM issues
+) t WHERE t.rn = 1;
@@ -0,0 +1,27 @@
+-- Add reconciliation columns to issues table
+ALTER TABLE issues ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE issues ADD COLUMN out_of_scope_at TIMESTAMP WITH TIME ZONE;
+
+-- Add reconciliation counters to sync_runs table
+ALTER TABLE sync_runs ADD COLUMN issues_deleted INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sync_runs ADD COLUMN issues_out_of_scope INTEGER NOT NULL DEFAULT 0;
+
+-- Recreate v_issues to exclude deleted and out of scope issues
+DROP VIEW IF EXISTS v_issues;
+
+CREATE VIEW v_issues AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY tracker_id ORDER BY updated_at DESC) as rn
+    FROM issues
+    WHERE deleted_at IS NULL
+        AND out_of_scope_at IS NULL
+) t WHERE t.rn = 1;
+
+-- Create view including deleted and out of scope issues
+CREATE OR REPLACE VIEW v_issues_all AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY tracker_id ORDER BY updated_at DESC) as rn
+    FROM issues
+) t WHERE t.rn = 1;
@@ -0,0 +1,10 @@
+-- Drop function
+DROP FUNCTION IF EXISTS issues_as_of(TIMESTAMP WITH TIME ZONE);
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_issue_history_open_version;
+DROP INDEX IF EXISTS idx_issue_history_validity;
+DROP INDEX IF EXISTS idx_issue_history_tracker_id;
+
+-- Drop issue_history table
+DROP TABLE IF EXISTS issue_history;
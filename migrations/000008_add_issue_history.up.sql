@@ -0,0 +1,42 @@
+-- Create issue_history table
+CREATE TABLE IF NOT EXISTS issue_history (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    tracker_id VARCHAR(255) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    valid_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    valid_to TIMESTAMP WITH TIME ZONE,
+    row_hash VARCHAR(64) NOT NULL,
+    summary TEXT,
+    queue_key VARCHAR(255),
+    type_display VARCHAR(255),
+    priority_display VARCHAR(255),
+    status_key VARCHAR(255),
+    status_display VARCHAR(255),
+    status_type VARCHAR(255),
+    resolution_display VARCHAR(255),
+    assignee_display VARCHAR(255),
+    project_display VARCHAR(255),
+    parent_key VARCHAR(255),
+    epic_display VARCHAR(255),
+    sprint_display TEXT,
+    components_display TEXT,
+    tags TEXT,
+    story_points DECIMAL(15,2),
+    deadline TIMESTAMP WITH TIME ZONE,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for issue_history
+CREATE INDEX IF NOT EXISTS idx_issue_history_tracker_id ON issue_history(tracker_id);
+CREATE INDEX IF NOT EXISTS idx_issue_history_validity ON issue_history(valid_from, valid_to);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_issue_history_open_version ON issue_history(tracker_id) WHERE valid_to IS NULL;
+
+-- Create function returning issue versions valid at the given moment
+CREATE OR REPLACE FUNCTION issues_as_of(as_of TIMESTAMP WITH TIME ZONE)
+RETURNS SETOF issue_history AS $$
+    SELECT *
+    FROM issue_history
+    WHERE valid_from <= as_of
+        AND (valid_to IS NULL OR valid_to > as_of)
+$$ LANGUAGE SQL STABLE;
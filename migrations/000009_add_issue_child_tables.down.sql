@@ -0,0 +1,13 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_issue_boards_board_id;
+DROP INDEX IF EXISTS idx_issue_sprints_sprint_id;
+DROP INDEX IF EXISTS idx_issue_components_component_id;
+DROP INDEX IF EXISTS idx_issue_followers_user_id;
+DROP INDEX IF EXISTS idx_issue_tags_tag;
+
+-- Drop child tables
+DROP TABLE IF EXISTS issue_boards;
+DROP TABLE IF EXISTS issue_sprints;
+DROP TABLE IF EXISTS issue_components;
+DROP TABLE IF EXISTS issue_followers;
+DROP TABLE IF EXISTS issue_tags;
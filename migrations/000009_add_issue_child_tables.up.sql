@@ -0,0 +1,50 @@
+-- Create issue_tags table
+CREATE TABLE IF NOT EXISTS issue_tags (
+    issue_tracker_id VARCHAR(255) NOT NULL,
+    issue_key VARCHAR(255) NOT NULL,
+    tag VARCHAR(255) NOT NULL,
+    CONSTRAINT issue_tags_pkey PRIMARY KEY (issue_tracker_id, tag)
+);
+
+-- Create issue_followers table
+CREATE TABLE IF NOT EXISTS issue_followers (
+    issue_tracker_id VARCHAR(255) NOT NULL,
+    issue_key VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    display VARCHAR(255),
+    CONSTRAINT issue_followers_pkey PRIMARY KEY (issue_tracker_id, user_id)
+);
+
+-- Create issue_components table
+CREATE TABLE IF NOT EXISTS issue_components (
+    issue_tracker_id VARCHAR(255) NOT NULL,
+    issue_key VARCHAR(255) NOT NULL,
+    component_id VARCHAR(255) NOT NULL,
+    display VARCHAR(255),
+    CONSTRAINT issue_components_pkey PRIMARY KEY (issue_tracker_id, component_id)
+);
+
+-- Create issue_sprints table
+CREATE TABLE IF NOT EXISTS issue_sprints (
+    issue_tracker_id VARCHAR(255) NOT NULL,
+    issue_key VARCHAR(255) NOT NULL,
+    sprint_id VARCHAR(255) NOT NULL,
+    display VARCHAR(255),
+    CONSTRAINT issue_sprints_pkey PRIMARY KEY (issue_tracker_id, sprint_id)
+);
+
+-- Create issue_boards table
+CREATE TABLE IF NOT EXISTS issue_boards (
+    issue_tracker_id VARCHAR(255) NOT NULL,
+    issue_key VARCHAR(255) NOT NULL,
+    board_id INTEGER NOT NULL,
+    name VARCHAR(255),
+    CONSTRAINT issue_boards_pkey PRIMARY KEY (issue_tracker_id, board_id)
+);
+
+-- Create indexes for lookups by entity
+CREATE INDEX IF NOT EXISTS idx_issue_tags_tag ON issue_tags(tag);
+CREATE INDEX IF NOT EXISTS idx_issue_followers_user_id ON issue_followers(user_id);
+CREATE INDEX IF NOT EXISTS idx_issue_components_component_id ON issue_components(component_id);
+CREATE INDEX IF NOT EXISTS idx_issue_sprints_sprint_id ON issue_sprints(sprint_id);
+CREATE INDEX IF NOT EXISTS idx_issue_boards_board_id ON issue_boards(board_id);
@@ -0,0 +1,56 @@
+-- Drop views depending on the new columns
+DROP VIEW IF EXISTS v_changelog;
+DROP VIEW IF EXISTS v_issues_all;
+DROP VIEW IF EXISTS v_issues;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_changelog_field_id;
+DROP INDEX IF EXISTS idx_issues_assignee_id;
+DROP INDEX IF EXISTS idx_issues_status_key;
+
+-- Remove field and value IDs from changelog table
+ALTER TABLE changelog DROP COLUMN to_key;
+ALTER TABLE changelog DROP COLUMN to_id;
+ALTER TABLE changelog DROP COLUMN from_key;
+ALTER TABLE changelog DROP COLUMN from_id;
+ALTER TABLE changelog DROP COLUMN field_id;
+
+-- Remove stable key and ID columns from issues table
+ALTER TABLE issues DROP COLUMN parent_id;
+ALTER TABLE issues DROP COLUMN epic_id;
+ALTER TABLE issues DROP COLUMN epic_key;
+ALTER TABLE issues DROP COLUMN project_id;
+ALTER TABLE issues DROP COLUMN created_by_id;
+ALTER TABLE issues DROP COLUMN assignee_id;
+ALTER TABLE issues DROP COLUMN resolution_id;
+ALTER TABLE issues DROP COLUMN resolution_key;
+ALTER TABLE issues DROP COLUMN priority_id;
+ALTER TABLE issues DROP COLUMN priority_key;
+ALTER TABLE issues DROP COLUMN type_id;
+ALTER TABLE issues DROP COLUMN type_key;
+ALTER TABLE issues DROP COLUMN status_id;
+ALTER TABLE issues DROP COLUMN status_key;
+
+-- Restore views
+CREATE VIEW v_issues AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY tracker_id ORDER BY updated_at DESC) as rn
+    FROM issues
+    WHERE deleted_at IS NULL
+        AND out_of_scope_at IS NULL
+) t WHERE t.rn = 1;
+
+CREATE VIEW v_issues_all AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY tracker_id ORDER BY updated_at DESC) as rn
+    FROM issues
+) t WHERE t.rn = 1;
+
+CREATE VIEW v_changelog AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY organization_id, tracker_id, field_display ORDER BY updated_at DESC) as rn
+    FROM changelog
+) t WHERE t.rn = 1;
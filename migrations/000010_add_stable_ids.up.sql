@@ -0,0 +1,55 @@
+-- Add stable key and ID columns to issues table
+ALTER TABLE issues ADD COLUMN status_key VARCHAR(255);
+ALTER TABLE issues ADD COLUMN status_id VARCHAR(255);
+ALTER TABLE issues ADD COLUMN type_key VARCHAR(255);
+ALTER TABLE issues ADD COLUMN type_id VARCHAR(255);
+ALTER TABLE issues ADD COLUMN priority_key VARCHAR(255);
+ALTER TABLE issues ADD COLUMN priority_id VARCHAR(255);
+ALTER TABLE issues ADD COLUMN resolution_key VARCHAR(255);
+ALTER TABLE issues ADD COLUMN resolution_id VARCHAR(255);
+ALTER TABLE issues ADD COLUMN assignee_id VARCHAR(255);
+ALTER TABLE issues ADD COLUMN created_by_id VARCHAR(255);
+ALTER TABLE issues ADD COLUMN project_id VARCHAR(255);
+ALTER TABLE issues ADD COLUMN epic_key VARCHAR(255);
+ALTER TABLE issues ADD COLUMN epic_id VARCHAR(255);
+ALTER TABLE issues ADD COLUMN parent_id VARCHAR(255);
+
+-- Add field and value IDs to changelog table
+ALTER TABLE changelog ADD COLUMN field_id VARCHAR(255);
+ALTER TABLE changelog ADD COLUMN from_id TEXT;
+ALTER TABLE changelog ADD COLUMN from_key TEXT;
+ALTER TABLE changelog ADD COLUMN to_id TEXT;
+ALTER TABLE changelog ADD COLUMN to_key TEXT;
+
+-- Create indexes for the new columns
+CREATE INDEX IF NOT EXISTS idx_issues_status_key ON issues(status_key);
+CREATE INDEX IF NOT EXISTS idx_issues_assignee_id ON issues(assignee_id);
+CREATE INDEX IF NOT EXISTS idx_changelog_field_id ON changelog(field_id);
+
+-- Recreate views to include the new columns
+DROP VIEW IF EXISTS v_issues;
+DROP VIEW IF EXISTS v_issues_all;
+DROP VIEW IF EXISTS v_changelog;
+
+CREATE VIEW v_issues AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY tracker_id ORDER BY updated_at DESC) as rn
+    FROM issues
+    WHERE deleted_at IS NULL
+        AND out_of_scope_at IS NULL
+) t WHERE t.rn = 1;
+
+CREATE VIEW v_issues_all AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY tracker_id ORDER BY updated_at DESC) as rn
+    FROM issues
+) t WHERE t.rn = 1;
+
+CREATE VIEW v_changelog AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY organization_id, tracker_id, field_display ORDER BY updated_at DESC) as rn
+    FROM changelog
+) t WHERE t.rn = 1;
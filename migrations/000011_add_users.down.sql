@@ -0,0 +1,41 @@
+-- Drop views depending on the new columns
+DROP VIEW IF EXISTS v_changelog;
+DROP VIEW IF EXISTS v_issues_all;
+DROP VIEW IF EXISTS v_issues;
+
+-- Remove user ID columns from issues and changelog tables
+DROP INDEX IF EXISTS idx_changelog_updated_by_id;
+ALTER TABLE changelog DROP COLUMN updated_by_id;
+ALTER TABLE issues DROP COLUMN resolved_by_id;
+ALTER TABLE issues DROP COLUMN updated_by_id;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_users_email;
+DROP INDEX IF EXISTS idx_users_login;
+
+-- Drop users table
+DROP TABLE IF EXISTS users;
+
+-- Restore views
+CREATE VIEW v_issues AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY tracker_id ORDER BY updated_at DESC) as rn
+    FROM issues
+    WHERE deleted_at IS NULL
+        AND out_of_scope_at IS NULL
+) t WHERE t.rn = 1;
+
+CREATE VIEW v_issues_all AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY tracker_id ORDER BY updated_at DESC) as rn
+    FROM issues
+) t WHERE t.rn = 1;
+
+CREATE VIEW v_changelog AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY organization_id, tracker_id, field_display ORDER BY updated_at DESC) as rn
+    FROM changelog
+) t WHERE t.rn = 1;
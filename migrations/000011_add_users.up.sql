@@ -0,0 +1,58 @@
+-- Create users table
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    uid VARCHAR(255) NOT NULL,
+    tracker_uid VARCHAR(255),
+    login VARCHAR(255),
+    email VARCHAR(255),
+    display VARCHAR(255),
+    first_name VARCHAR(255),
+    last_name VARCHAR(255),
+    external BOOLEAN,
+    dismissed BOOLEAN,
+    cloud_uid VARCHAR(255),
+    passport_uid BIGINT,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT users_organization_id_uid_key UNIQUE (organization_id, uid)
+);
+
+-- Create indexes for users
+CREATE INDEX IF NOT EXISTS idx_users_login ON users(login);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+
+-- Add user ID columns to issues and changelog tables
+ALTER TABLE issues ADD COLUMN updated_by_id VARCHAR(255);
+ALTER TABLE issues ADD COLUMN resolved_by_id VARCHAR(255);
+ALTER TABLE changelog ADD COLUMN updated_by_id VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_changelog_updated_by_id ON changelog(updated_by_id);
+
+-- Recreate views to include the new columns
+DROP VIEW IF EXISTS v_issues;
+DROP VIEW IF EXISTS v_issues_all;
+DROP VIEW IF EXISTS v_changelog;
+
+CREATE VIEW v_issues AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY tracker_id ORDER BY updated_at DESC) as rn
+    FROM issues
+    WHERE deleted_at IS NULL
+        AND out_of_scope_at IS NULL
+) t WHERE t.rn = 1;
+
+CREATE VIEW v_issues_all AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY tracker_id ORDER BY updated_at DESC) as rn
+    FROM issues
+) t WHERE t.rn = 1;
+
+CREATE VIEW v_changelog AS
+SELECT * FROM (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY organization_id, tracker_id, field_display ORDER BY updated_at DESC) as rn
+    FROM changelog
+) t WHERE t.rn = 1;
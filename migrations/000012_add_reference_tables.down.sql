@@ -0,0 +1,12 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_issues_project_id;
+DROP INDEX IF EXISTS idx_issues_queue_key;
+DROP INDEX IF EXISTS idx_versions_queue_key;
+DROP INDEX IF EXISTS idx_components_queue_key;
+DROP INDEX IF EXISTS idx_queues_key;
+
+-- Drop reference tables
+DROP TABLE IF EXISTS versions;
+DROP TABLE IF EXISTS components;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS queues;
@@ -0,0 +1,78 @@
+-- Create queues table
+CREATE TABLE IF NOT EXISTS queues (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    tracker_id VARCHAR(255) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    description TEXT,
+    lead_id VARCHAR(255),
+    lead_display VARCHAR(255),
+    assign_auto BOOLEAN,
+    default_type_key VARCHAR(255),
+    default_type_display VARCHAR(255),
+    default_priority_key VARCHAR(255),
+    default_priority_display VARCHAR(255),
+    deny_voting BOOLEAN,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT queues_organization_id_tracker_id_key UNIQUE (organization_id, tracker_id)
+);
+
+-- Create projects table
+CREATE TABLE IF NOT EXISTS projects (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    tracker_id VARCHAR(255) NOT NULL,
+    key VARCHAR(255),
+    name VARCHAR(255),
+    description TEXT,
+    lead_id VARCHAR(255),
+    lead_display VARCHAR(255),
+    status VARCHAR(255),
+    start_date TIMESTAMP WITH TIME ZONE,
+    end_date TIMESTAMP WITH TIME ZONE,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT projects_organization_id_tracker_id_key UNIQUE (organization_id, tracker_id)
+);
+
+-- Create components table
+CREATE TABLE IF NOT EXISTS components (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    tracker_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    queue_key VARCHAR(255),
+    description TEXT,
+    lead_id VARCHAR(255),
+    lead_display VARCHAR(255),
+    assign_auto BOOLEAN,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT components_organization_id_tracker_id_key UNIQUE (organization_id, tracker_id)
+);
+
+-- Create versions table
+CREATE TABLE IF NOT EXISTS versions (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    tracker_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    queue_key VARCHAR(255),
+    description TEXT,
+    start_date TIMESTAMP WITH TIME ZONE,
+    due_date TIMESTAMP WITH TIME ZONE,
+    released BOOLEAN,
+    archived BOOLEAN,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT versions_organization_id_tracker_id_key UNIQUE (organization_id, tracker_id)
+);
+
+-- Create indexes for joins from issues
+CREATE INDEX IF NOT EXISTS idx_queues_key ON queues(key);
+CREATE INDEX IF NOT EXISTS idx_components_queue_key ON components(queue_key);
+CREATE INDEX IF NOT EXISTS idx_versions_queue_key ON versions(queue_key);
+CREATE INDEX IF NOT EXISTS idx_issues_queue_key ON issues(queue_key);
+CREATE INDEX IF NOT EXISTS idx_issues_project_id ON issues(project_id);
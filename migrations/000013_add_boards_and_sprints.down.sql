@@ -0,0 +1,12 @@
+-- Drop view
+DROP VIEW IF EXISTS v_sprint_commitment;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_changelog_issue_key_field_id;
+DROP INDEX IF EXISTS idx_issue_sprint_membership_sprint_id;
+DROP INDEX IF EXISTS idx_sprints_board_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS issue_sprint_membership;
+DROP TABLE IF EXISTS sprints;
+DROP TABLE IF EXISTS boards;
@@ -0,0 +1,82 @@
+-- Create boards table
+CREATE TABLE IF NOT EXISTS boards (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    board_id INTEGER NOT NULL,
+    name VARCHAR(255),
+    query TEXT,
+    use_ranking BOOLEAN,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT boards_organization_id_board_id_key UNIQUE (organization_id, board_id)
+);
+
+-- Create sprints table
+CREATE TABLE IF NOT EXISTS sprints (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    tracker_id VARCHAR(255) NOT NULL,
+    board_id INTEGER NOT NULL,
+    name VARCHAR(255),
+    status VARCHAR(255),
+    archived BOOLEAN,
+    created_by_id VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE,
+    start_date DATE,
+    end_date DATE,
+    start_date_time TIMESTAMP WITH TIME ZONE,
+    end_date_time TIMESTAMP WITH TIME ZONE,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT sprints_organization_id_tracker_id_key UNIQUE (organization_id, tracker_id)
+);
+
+-- Create issue_sprint_membership table
+CREATE TABLE IF NOT EXISTS issue_sprint_membership (
+    issue_key VARCHAR(255) NOT NULL,
+    sprint_id VARCHAR(255) NOT NULL,
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    removed_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT issue_sprint_membership_pkey PRIMARY KEY (issue_key, sprint_id, added_at)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_sprints_board_id ON sprints(board_id);
+CREATE INDEX IF NOT EXISTS idx_issue_sprint_membership_sprint_id ON issue_sprint_membership(sprint_id);
+CREATE INDEX IF NOT EXISTS idx_changelog_issue_key_field_id ON changelog(issue_key, field_id);
+
+-- Committed and completed issues and story points per sprint. An issue is
+-- committed when it was in the sprint at the sprint start and completed when
+-- it was in the sprint at the sprint end and resolved by then.
+CREATE OR REPLACE VIEW v_sprint_commitment AS
+WITH bounds AS (
+    SELECT
+        s.tracker_id AS sprint_id,
+        s.board_id,
+        s.name,
+        s.status,
+        COALESCE(s.start_date_time, s.start_date::timestamptz) AS sprint_start,
+        COALESCE(s.end_date_time, (s.end_date + 1)::timestamptz) AS sprint_end
+    FROM sprints s
+)
+SELECT
+    b.sprint_id,
+    b.board_id,
+    b.name,
+    b.status,
+    b.sprint_start,
+    b.sprint_end,
+    COUNT(DISTINCT i.key) FILTER (WHERE m.added_at <= b.sprint_start
+        AND (m.removed_at IS NULL OR m.removed_at > b.sprint_start)) AS committed_issues,
+    COALESCE(SUM(i.story_points) FILTER (WHERE m.added_at <= b.sprint_start
+        AND (m.removed_at IS NULL OR m.removed_at > b.sprint_start)), 0) AS committed_points,
+    COUNT(DISTINCT i.key) FILTER (WHERE m.added_at <= b.sprint_end
+        AND (m.removed_at IS NULL OR m.removed_at >= b.sprint_end)
+        AND i.resolved_at <= b.sprint_end) AS completed_issues,
+    COALESCE(SUM(i.story_points) FILTER (WHERE m.added_at <= b.sprint_end
+        AND (m.removed_at IS NULL OR m.removed_at >= b.sprint_end)
+        AND i.resolved_at <= b.sprint_end), 0) AS completed_points
+FROM bounds b
+LEFT JOIN issue_sprint_membership m ON m.sprint_id = b.sprint_id
+LEFT JOIN v_issues i ON i.key = m.issue_key
+GROUP BY b.sprint_id, b.board_id, b.name, b.status, b.sprint_start, b.sprint_end;
@@ -0,0 +1,35 @@
+-- Create workflows table
+CREATE TABLE IF NOT EXISTS workflows (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    queue_key VARCHAR(255) NOT NULL,
+    workflow_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    initial_status_key VARCHAR(255),
+    issue_types TEXT,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT workflows_organization_id_queue_key_workflow_id_key UNIQUE (organization_id, queue_key, workflow_id)
+);
+
+-- Create workflow_statuses table
+CREATE TABLE IF NOT EXISTS workflow_statuses (
+    organization_id VARCHAR(255) NOT NULL,
+    queue_key VARCHAR(255) NOT NULL,
+    workflow_id VARCHAR(255) NOT NULL,
+    status_key VARCHAR(255) NOT NULL,
+    status_display VARCHAR(255),
+    position INTEGER,
+    CONSTRAINT workflow_statuses_pkey PRIMARY KEY (organization_id, queue_key, workflow_id, status_key)
+);
+
+-- Create workflow_transitions table
+CREATE TABLE IF NOT EXISTS workflow_transitions (
+    organization_id VARCHAR(255) NOT NULL,
+    queue_key VARCHAR(255) NOT NULL,
+    workflow_id VARCHAR(255) NOT NULL,
+    from_status_key VARCHAR(255) NOT NULL,
+    transition_id VARCHAR(255) NOT NULL,
+    display VARCHAR(255),
+    to_status_key VARCHAR(255) NOT NULL,
+    CONSTRAINT workflow_transitions_pkey PRIMARY KEY (organization_id, queue_key, workflow_id, from_status_key, transition_id)
+);
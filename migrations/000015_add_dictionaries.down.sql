@@ -0,0 +1,12 @@
+-- Drop view
+DROP VIEW IF EXISTS v_issue_labels;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_resolutions_key;
+DROP INDEX IF EXISTS idx_priorities_key;
+DROP INDEX IF EXISTS idx_issue_types_key;
+
+-- Drop dictionary tables
+DROP TABLE IF EXISTS resolutions;
+DROP TABLE IF EXISTS priorities;
+DROP TABLE IF EXISTS issue_types;
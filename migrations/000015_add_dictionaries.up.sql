@@ -0,0 +1,70 @@
+-- Create issue_types table
+CREATE TABLE IF NOT EXISTS issue_types (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    tracker_id VARCHAR(255) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    name_en VARCHAR(255),
+    name_ru VARCHAR(255),
+    names JSONB,
+    description TEXT,
+    sort_order INTEGER,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT issue_types_organization_id_tracker_id_key UNIQUE (organization_id, tracker_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_issue_types_key ON issue_types(key);
+
+-- Create priorities table
+CREATE TABLE IF NOT EXISTS priorities (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    tracker_id VARCHAR(255) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    name_en VARCHAR(255),
+    name_ru VARCHAR(255),
+    names JSONB,
+    description TEXT,
+    sort_order INTEGER,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT priorities_organization_id_tracker_id_key UNIQUE (organization_id, tracker_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_priorities_key ON priorities(key);
+
+-- Create resolutions table
+CREATE TABLE IF NOT EXISTS resolutions (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    tracker_id VARCHAR(255) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    name_en VARCHAR(255),
+    name_ru VARCHAR(255),
+    names JSONB,
+    description TEXT,
+    sort_order INTEGER,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT resolutions_organization_id_tracker_id_key UNIQUE (organization_id, tracker_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_resolutions_key ON resolutions(key);
+
+-- Issue type, priority and resolution names in English with the priority order
+CREATE OR REPLACE VIEW v_issue_labels AS
+SELECT
+    i.tracker_id,
+    i.key,
+    COALESCE(t.name_en, t.name, i.type_display) AS type_name_en,
+    COALESCE(p.name_en, p.name, i.priority_display) AS priority_name_en,
+    p.sort_order AS priority_order,
+    COALESCE(r.name_en, r.name, i.resolution_display) AS resolution_name_en
+FROM v_issues i
+LEFT JOIN issue_types t ON t.organization_id = i.organization_id AND t.key = i.type_key
+LEFT JOIN priorities p ON p.organization_id = i.organization_id AND p.key = i.priority_key
+LEFT JOIN resolutions r ON r.organization_id = i.organization_id AND r.key = i.resolution_key;
@@ -0,0 +1,5 @@
+-- Drop index
+DROP INDEX IF EXISTS idx_issue_checklist_items_assignee_id;
+
+-- Drop issue_checklist_items table
+DROP TABLE IF EXISTS issue_checklist_items;
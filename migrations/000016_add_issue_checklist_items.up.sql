@@ -0,0 +1,19 @@
+-- Create issue_checklist_items table
+CREATE TABLE IF NOT EXISTS issue_checklist_items (
+    issue_tracker_id VARCHAR(255) NOT NULL,
+    issue_key VARCHAR(255) NOT NULL,
+    item_id VARCHAR(255) NOT NULL,
+    position INTEGER NOT NULL,
+    text TEXT,
+    checked BOOLEAN NOT NULL DEFAULT FALSE,
+    assignee_id VARCHAR(255),
+    assignee_display VARCHAR(255),
+    deadline TIMESTAMP WITH TIME ZONE,
+    deadline_type VARCHAR(255),
+    deadline_exceeded BOOLEAN,
+    item_type VARCHAR(255),
+    CONSTRAINT issue_checklist_items_pkey PRIMARY KEY (issue_tracker_id, item_id)
+);
+
+-- Create index for lookups by assignee
+CREATE INDEX IF NOT EXISTS idx_issue_checklist_items_assignee_id ON issue_checklist_items(assignee_id);
@@ -0,0 +1,9 @@
+-- Drop view
+DROP VIEW IF EXISTS v_queue_attachments;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_attachments_content_sha256;
+DROP INDEX IF EXISTS idx_attachments_issue_key;
+
+-- Drop attachments table
+DROP TABLE IF EXISTS attachments;
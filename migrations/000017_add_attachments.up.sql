@@ -0,0 +1,38 @@
+-- Create attachments table
+CREATE TABLE IF NOT EXISTS attachments (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    tracker_id VARCHAR(255) NOT NULL,
+    issue_key VARCHAR(255) NOT NULL,
+    name TEXT,
+    size BIGINT,
+    mimetype VARCHAR(255),
+    created_by_id VARCHAR(255),
+    created_by_display VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE,
+    content_url TEXT,
+    content_sha256 VARCHAR(64),
+    storage_key TEXT,
+    downloaded_at TIMESTAMP WITH TIME ZONE,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT attachments_organization_id_tracker_id_key UNIQUE (organization_id, tracker_id)
+);
+
+-- Create indexes for attachments
+CREATE INDEX IF NOT EXISTS idx_attachments_issue_key ON attachments(issue_key);
+CREATE INDEX IF NOT EXISTS idx_attachments_content_sha256 ON attachments(content_sha256);
+
+-- Attachment count, size and activity per queue
+CREATE OR REPLACE VIEW v_queue_attachments AS
+SELECT
+    i.queue_key,
+    COUNT(*) AS attachments,
+    COALESCE(SUM(a.size), 0) AS total_size,
+    MAX(a.size) AS max_size,
+    COUNT(DISTINCT a.content_sha256) AS unique_contents,
+    MIN(a.created_at) AS first_created_at,
+    MAX(a.created_at) AS last_created_at
+FROM attachments a
+JOIN issues i ON i.organization_id = a.organization_id AND i.key = a.issue_key
+GROUP BY i.queue_key;
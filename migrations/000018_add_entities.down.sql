@@ -0,0 +1,5 @@
+-- Drop entity tables
+DROP VIEW IF EXISTS v_entity_issues;
+DROP TABLE IF EXISTS entity_status_history;
+DROP TABLE IF EXISTS entity_links;
+DROP TABLE IF EXISTS entities;
@@ -0,0 +1,80 @@
+-- Create entities table for projects, portfolios and goals
+CREATE TABLE IF NOT EXISTS entities (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    tracker_id VARCHAR(255) NOT NULL,
+    short_id INTEGER,
+    entity_type VARCHAR(32) NOT NULL,
+    summary TEXT,
+    description TEXT,
+    entity_status VARCHAR(255),
+    lead_id VARCHAR(255),
+    lead_display VARCHAR(255),
+    start_date TIMESTAMP WITH TIME ZONE,
+    end_date TIMESTAMP WITH TIME ZONE,
+    end_date_type VARCHAR(32),
+    parent_id VARCHAR(255),
+    parent_type VARCHAR(32),
+    tags TEXT,
+    fields JSONB,
+    created_by_id VARCHAR(255),
+    created_by_display VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT entities_organization_id_tracker_id_key UNIQUE (organization_id, tracker_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_entities_entity_type ON entities(entity_type);
+CREATE INDEX IF NOT EXISTS idx_entities_parent_id ON entities(parent_id);
+
+-- Create entity_links table
+CREATE TABLE IF NOT EXISTS entity_links (
+    organization_id VARCHAR(255) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    link_type VARCHAR(255) NOT NULL,
+    direction VARCHAR(32) NOT NULL,
+    linked_id VARCHAR(255) NOT NULL,
+    linked_type VARCHAR(32),
+    linked_key VARCHAR(255),
+    linked_display TEXT,
+    CONSTRAINT entity_links_pkey PRIMARY KEY (organization_id, entity_id, link_type, direction, linked_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_links_linked_key ON entity_links(linked_key);
+
+-- Create entity_status_history table
+CREATE TABLE IF NOT EXISTS entity_status_history (
+    organization_id VARCHAR(255) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE,
+    author_id VARCHAR(255),
+    author_display VARCHAR(255),
+    from_status VARCHAR(255),
+    to_status VARCHAR(255),
+    CONSTRAINT entity_status_history_pkey PRIMARY KEY (organization_id, entity_id, event_id)
+);
+
+-- Issues related to an entity, either by the issue project or by a link
+CREATE OR REPLACE VIEW v_entity_issues AS
+SELECT
+    e.tracker_id AS entity_id,
+    e.entity_type,
+    e.summary AS entity_summary,
+    i.key AS issue_key,
+    'project' AS relation
+FROM entities e
+JOIN issues i ON i.organization_id = e.organization_id AND i.project_id = e.short_id::TEXT
+WHERE e.entity_type = 'project'
+UNION ALL
+SELECT
+    e.tracker_id AS entity_id,
+    e.entity_type,
+    e.summary AS entity_summary,
+    l.linked_key AS issue_key,
+    l.link_type AS relation
+FROM entities e
+JOIN entity_links l ON l.organization_id = e.organization_id AND l.entity_id = e.tracker_id
+WHERE l.linked_key IS NOT NULL AND l.linked_key <> '';
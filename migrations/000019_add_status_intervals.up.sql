@@ -0,0 +1,45 @@
+-- Create status_intervals table maintained by the service from the status changelog
+CREATE TABLE IF NOT EXISTS status_intervals (
+    organization_id VARCHAR(255) NOT NULL,
+    issue_key VARCHAR(255) NOT NULL,
+    position INTEGER NOT NULL,
+    status_key VARCHAR(255) NOT NULL,
+    status_display VARCHAR(255),
+    status_type VARCHAR(255),
+    entered_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    exited_at TIMESTAMP WITH TIME ZONE,
+    duration_minutes DOUBLE PRECISION,
+    CONSTRAINT status_intervals_pkey PRIMARY KEY (organization_id, issue_key, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_intervals_status_key ON status_intervals(status_key);
+CREATE INDEX IF NOT EXISTS idx_status_intervals_status_type ON status_intervals(status_type);
+
+-- Rebuild v_issue_statuses from the status field ID and status keys. The
+-- previous status of the first change is counted from the issue creation.
+DROP VIEW IF EXISTS v_issue_statuses;
+
+CREATE VIEW v_issue_statuses AS
+SELECT
+    c.issue_key,
+    i.created_at as issue_created,
+    COALESCE(c.prev_updated_at, i.created_at) as from_status_timestamp,
+    c.updated_at as to_status_timestamp,
+    EXTRACT(EPOCH FROM (c.updated_at - COALESCE(c.prev_updated_at, i.created_at)))/60 as from_previous_minutes,
+    c.from_display as from_status,
+    c.to_display as to_status,
+    EXTRACT(EPOCH FROM (c.updated_at - i.created_at))/60 as from_created_minutes,
+    st_from.status_type as from_status_type,
+    st_to.status_type as to_status_type,
+    c.from_key as from_status_key,
+    c.to_key as to_status_key
+FROM (
+    SELECT *,
+           LAG(updated_at) OVER (PARTITION BY issue_key ORDER BY updated_at, tracker_id) as prev_updated_at
+    FROM changelog
+    WHERE field_id = 'status'
+) c
+JOIN issues i ON c.issue_key = i.key
+LEFT JOIN status_types st_from ON c.from_key = st_from.status_key
+LEFT JOIN status_types st_to ON c.to_key = st_to.status_key
+ORDER BY c.issue_key, c.updated_at;
@@ -0,0 +1,2 @@
+-- Drop issue_flow_metrics table
+DROP TABLE IF EXISTS issue_flow_metrics;
@@ -0,0 +1,20 @@
+-- Create issue_flow_metrics table maintained by the service from status intervals
+CREATE TABLE IF NOT EXISTS issue_flow_metrics (
+    organization_id VARCHAR(255) NOT NULL,
+    issue_key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    first_in_progress_at TIMESTAMP WITH TIME ZONE,
+    first_done_at TIMESTAMP WITH TIME ZONE,
+    lead_time_minutes DOUBLE PRECISION,
+    cycle_time_minutes DOUBLE PRECISION,
+    new_minutes DOUBLE PRECISION NOT NULL DEFAULT 0,
+    in_progress_minutes DOUBLE PRECISION NOT NULL DEFAULT 0,
+    paused_minutes DOUBLE PRECISION NOT NULL DEFAULT 0,
+    done_minutes DOUBLE PRECISION NOT NULL DEFAULT 0,
+    cancelled_minutes DOUBLE PRECISION NOT NULL DEFAULT 0,
+    reopen_count INTEGER NOT NULL DEFAULT 0,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT issue_flow_metrics_pkey PRIMARY KEY (organization_id, issue_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_issue_flow_metrics_first_done_at ON issue_flow_metrics(first_done_at);
@@ -0,0 +1,10 @@
+-- Drop business time durations
+ALTER TABLE issue_flow_metrics DROP COLUMN IF EXISTS cancelled_business_minutes;
+ALTER TABLE issue_flow_metrics DROP COLUMN IF EXISTS done_business_minutes;
+ALTER TABLE issue_flow_metrics DROP COLUMN IF EXISTS paused_business_minutes;
+ALTER TABLE issue_flow_metrics DROP COLUMN IF EXISTS in_progress_business_minutes;
+ALTER TABLE issue_flow_metrics DROP COLUMN IF EXISTS new_business_minutes;
+ALTER TABLE issue_flow_metrics DROP COLUMN IF EXISTS cycle_time_business_minutes;
+ALTER TABLE issue_flow_metrics DROP COLUMN IF EXISTS lead_time_business_minutes;
+
+ALTER TABLE status_intervals DROP COLUMN IF EXISTS business_minutes;
@@ -0,0 +1,10 @@
+-- Add business time durations computed with the working calendar
+ALTER TABLE status_intervals ADD COLUMN business_minutes DOUBLE PRECISION;
+
+ALTER TABLE issue_flow_metrics ADD COLUMN lead_time_business_minutes DOUBLE PRECISION;
+ALTER TABLE issue_flow_metrics ADD COLUMN cycle_time_business_minutes DOUBLE PRECISION;
+ALTER TABLE issue_flow_metrics ADD COLUMN new_business_minutes DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE issue_flow_metrics ADD COLUMN in_progress_business_minutes DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE issue_flow_metrics ADD COLUMN paused_business_minutes DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE issue_flow_metrics ADD COLUMN done_business_minutes DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE issue_flow_metrics ADD COLUMN cancelled_business_minutes DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Drop queue_daily_snapshot table
+DROP TABLE IF EXISTS queue_daily_snapshot;
@@ -0,0 +1,15 @@
+-- Create queue_daily_snapshot table with the number of issues and story
+-- points per queue and status at the end of every day
+CREATE TABLE IF NOT EXISTS queue_daily_snapshot (
+    snapshot_date DATE NOT NULL,
+    queue_key VARCHAR(255) NOT NULL,
+    status_key VARCHAR(255) NOT NULL,
+    status_display VARCHAR(255),
+    status_type VARCHAR(255),
+    issues INTEGER NOT NULL,
+    story_points DECIMAL(15,2) NOT NULL DEFAULT 0,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT queue_daily_snapshot_pkey PRIMARY KEY (snapshot_date, queue_key, status_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_queue_daily_snapshot_queue_key ON queue_daily_snapshot(queue_key, snapshot_date);
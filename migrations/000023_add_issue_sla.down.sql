@@ -0,0 +1,8 @@
+-- Drop v_sla_breach_rate view
+DROP VIEW IF EXISTS v_sla_breach_rate;
+
+-- Drop index
+DROP INDEX IF EXISTS idx_issue_sla_settings_id;
+
+-- Drop issue_sla table
+DROP TABLE IF EXISTS issue_sla;
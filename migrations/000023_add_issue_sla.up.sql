@@ -0,0 +1,40 @@
+-- Create issue_sla table with the SLA timers of every issue. Thresholds and
+-- durations are stored in milliseconds as returned by the API.
+CREATE TABLE IF NOT EXISTS issue_sla (
+    issue_tracker_id VARCHAR(255) NOT NULL,
+    issue_key VARCHAR(255) NOT NULL,
+    sla_id VARCHAR(255) NOT NULL,
+    settings_id VARCHAR(255),
+    clock_status VARCHAR(255),
+    violation_status VARCHAR(255),
+    warn_threshold BIGINT,
+    fail_threshold BIGINT,
+    started_at TIMESTAMP WITH TIME ZONE,
+    paused_at TIMESTAMP WITH TIME ZONE,
+    paused_duration BIGINT,
+    warn_at TIMESTAMP WITH TIME ZONE,
+    deadline TIMESTAMP WITH TIME ZONE,
+    failed_at TIMESTAMP WITH TIME ZONE,
+    spent BIGINT,
+    CONSTRAINT issue_sla_pkey PRIMARY KEY (issue_tracker_id, sla_id)
+);
+
+-- Create index for lookups by SLA settings
+CREATE INDEX IF NOT EXISTS idx_issue_sla_settings_id ON issue_sla(settings_id);
+
+-- Create v_sla_breach_rate view with the share of breached SLA timers per
+-- queue, priority and month the timer started
+CREATE VIEW v_sla_breach_rate AS
+SELECT
+    date_trunc('month', s.started_at)::date AS month,
+    i.queue_key,
+    i.priority_key,
+    MAX(i.priority_display) AS priority_display,
+    COUNT(*) AS timers,
+    COUNT(*) FILTER (WHERE s.violation_status = 'WARN_THRESHOLD_EXCEEDED') AS warned,
+    COUNT(*) FILTER (WHERE s.failed_at IS NOT NULL) AS breached,
+    ROUND(COUNT(*) FILTER (WHERE s.failed_at IS NOT NULL)::numeric / COUNT(*), 4) AS breach_rate
+FROM issue_sla s
+JOIN v_issues i ON i.tracker_id = s.issue_tracker_id
+WHERE s.started_at IS NOT NULL
+GROUP BY date_trunc('month', s.started_at)::date, i.queue_key, i.priority_key;
@@ -0,0 +1,6 @@
+-- Drop v_sprint_velocity view
+DROP VIEW IF EXISTS v_sprint_velocity;
+
+-- Drop sprint_burndown and sprint_scope tables
+DROP TABLE IF EXISTS sprint_burndown;
+DROP TABLE IF EXISTS sprint_scope;
@@ -0,0 +1,63 @@
+-- Create sprint_scope table with the committed, added, removed, re-estimated
+-- and completed scope of every started sprint, maintained by the service
+CREATE TABLE IF NOT EXISTS sprint_scope (
+    sprint_id VARCHAR(255) NOT NULL,
+    board_id INTEGER NOT NULL,
+    sprint_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    sprint_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    committed_issues INTEGER NOT NULL,
+    committed_points DECIMAL(15,2) NOT NULL,
+    added_issues INTEGER NOT NULL,
+    added_points DECIMAL(15,2) NOT NULL,
+    removed_issues INTEGER NOT NULL,
+    removed_points DECIMAL(15,2) NOT NULL,
+    reestimated_points DECIMAL(15,2) NOT NULL,
+    completed_issues INTEGER NOT NULL,
+    completed_points DECIMAL(15,2) NOT NULL,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT sprint_scope_pkey PRIMARY KEY (sprint_id)
+);
+
+-- Create sprint_burndown table with the scope and completed work of every
+-- sprint at the end of each day
+CREATE TABLE IF NOT EXISTS sprint_burndown (
+    sprint_id VARCHAR(255) NOT NULL,
+    day DATE NOT NULL,
+    scope_issues INTEGER NOT NULL,
+    scope_points DECIMAL(15,2) NOT NULL,
+    completed_issues INTEGER NOT NULL,
+    completed_points DECIMAL(15,2) NOT NULL,
+    remaining_issues INTEGER NOT NULL,
+    remaining_points DECIMAL(15,2) NOT NULL,
+    ideal_points DECIMAL(15,2) NOT NULL,
+    CONSTRAINT sprint_burndown_pkey PRIMARY KEY (sprint_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sprint_scope_board_id ON sprint_scope(board_id, sprint_start);
+
+-- Create v_sprint_velocity view with the completed points of every sprint and
+-- the average of the last three sprints of the board
+CREATE VIEW v_sprint_velocity AS
+SELECT
+    sc.board_id,
+    b.name AS board_name,
+    sc.sprint_id,
+    s.name AS sprint_name,
+    s.status,
+    sc.sprint_start,
+    sc.sprint_end,
+    sc.committed_points,
+    sc.added_points,
+    sc.removed_points,
+    sc.reestimated_points,
+    sc.completed_points,
+    CASE WHEN sc.committed_points > 0
+        THEN ROUND(sc.completed_points / sc.committed_points, 4)
+    END AS completion_ratio,
+    ROUND(AVG(sc.completed_points) OVER (
+        PARTITION BY sc.board_id ORDER BY sc.sprint_start
+        ROWS BETWEEN 2 PRECEDING AND CURRENT ROW
+    ), 2) AS velocity_avg_3
+FROM sprint_scope sc
+JOIN sprints s ON s.tracker_id = sc.sprint_id
+LEFT JOIN boards b ON b.board_id = sc.board_id;
@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_worklogs_author_id;
+DROP INDEX IF EXISTS idx_worklogs_issue_key;
+
+-- Drop worklogs table
+DROP TABLE IF EXISTS worklogs;
@@ -0,0 +1,23 @@
+-- Create worklogs table with the time spent records of synced issues
+CREATE TABLE IF NOT EXISTS worklogs (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    tracker_id VARCHAR(255) NOT NULL,
+    issue_key VARCHAR(255) NOT NULL,
+    author_id VARCHAR(255),
+    author_display VARCHAR(255),
+    updated_by_id VARCHAR(255),
+    updated_by_display VARCHAR(255),
+    comment TEXT,
+    start TIMESTAMP WITH TIME ZONE,
+    duration VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    created_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at_db TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT worklogs_organization_id_tracker_id_key UNIQUE (organization_id, tracker_id)
+);
+
+-- Create indexes for worklogs
+CREATE INDEX IF NOT EXISTS idx_worklogs_issue_key ON worklogs(issue_key);
+CREATE INDEX IF NOT EXISTS idx_worklogs_author_id ON worklogs(author_id);
@@ -0,0 +1,84 @@
+// Package blobstore stores content-addressed binary objects in a local
+// directory or an S3-compatible bucket
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Store is a destination for binary objects
+type Store interface {
+	// Exists reports whether an object with the key is already stored
+	Exists(ctx context.Context, key string) (bool, error)
+	// Put stores size bytes read from r under the key. contentSHA256 is the hex
+	// encoded SHA-256 of the content.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentSHA256, contentType string) error
+}
+
+// ContentKey returns the key of an object addressed by the hex encoded SHA-256 of its content
+func ContentKey(sha256 string) string {
+	if len(sha256) < 2 {
+		return "sha256/" + sha256
+	}
+	return "sha256/" + sha256[:2] + "/" + sha256
+}
+
+// DirStore stores objects as files below a local directory
+type DirStore struct {
+	root string
+}
+
+// NewDirStore creates a store writing to the given directory
+func NewDirStore(root string) (*DirStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", root, err)
+	}
+	return &DirStore{root: root}, nil
+}
+
+// Exists reports whether the file of the key exists
+func (d *DirStore) Exists(_ context.Context, key string) (bool, error) {
+	_, err := os.Stat(d.path(key))
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to stat %s: %w", key, err)
+}
+
+// Put writes the object to a temporary file and renames it into place
+func (d *DirStore) Put(_ context.Context, key string, r io.Reader, _ int64, _, _ string) error {
+	path := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", key, err)
+	}
+	return nil
+}
+
+// path returns the file path of the key
+func (d *DirStore) path(key string) string {
+	return filepath.Join(d.root, filepath.FromSlash(key))
+}
@@ -0,0 +1,182 @@
+// Package calendar computes durations in working time according to working
+// hours, weekends and holidays in a time zone
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	_ "time/tzdata" // time zones for images without a zoneinfo database
+)
+
+// dateLayout is the layout of holiday dates
+const dateLayout = "2006-01-02"
+
+// Config describes a working calendar
+type Config struct {
+	TimeZone     string   // IANA time zone, e.g. Europe/Moscow
+	WorkStart    string   // start of the working day, HH:MM
+	WorkEnd      string   // end of the working day, HH:MM
+	Weekends     []string // weekday names, e.g. saturday, sunday
+	HolidaysFile string   // optional file with one YYYY-MM-DD date per line
+}
+
+// Calendar is a working calendar
+type Calendar struct {
+	loc      *time.Location
+	start    time.Duration // offset of the working day start from midnight
+	end      time.Duration // offset of the working day end from midnight
+	weekends map[time.Weekday]bool
+	holidays map[string]bool
+}
+
+// New creates a calendar from the configuration
+func New(cfg Config) (*Calendar, error) {
+	loc, err := time.LoadLocation(cfg.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load time zone %q: %w", cfg.TimeZone, err)
+	}
+
+	start, err := parseClock(cfg.WorkStart)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClock(cfg.WorkEnd)
+	if err != nil {
+		return nil, err
+	}
+	if end <= start {
+		return nil, fmt.Errorf("working day end %s must be after its start %s", cfg.WorkEnd, cfg.WorkStart)
+	}
+
+	weekends := make(map[time.Weekday]bool)
+	for _, name := range cfg.Weekends {
+		day, err := parseWeekday(name)
+		if err != nil {
+			return nil, err
+		}
+		weekends[day] = true
+	}
+
+	holidays := make(map[string]bool)
+	if cfg.HolidaysFile != "" {
+		dates, err := LoadHolidays(cfg.HolidaysFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, date := range dates {
+			holidays[date.Format(dateLayout)] = true
+		}
+	}
+
+	return &Calendar{loc: loc, start: start, end: end, weekends: weekends, holidays: holidays}, nil
+}
+
+// LoadHolidays reads holiday dates from a file with one YYYY-MM-DD date per
+// line. Empty lines and text after # are ignored.
+func LoadHolidays(path string) ([]time.Time, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open holidays file: %w", err)
+	}
+	defer file.Close()
+
+	var dates []time.Time
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		date, err := time.Parse(dateLayout, text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q in holidays file on line %d", text, line)
+		}
+		dates = append(dates, date)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read holidays file: %w", err)
+	}
+	return dates, nil
+}
+
+// Location returns the time zone of the calendar
+func (c *Calendar) Location() *time.Location {
+	return c.loc
+}
+
+// IsWorkingDay reports whether the date of t in the calendar time zone is neither a weekend nor a holiday
+func (c *Calendar) IsWorkingDay(t time.Time) bool {
+	t = t.In(c.loc)
+	return !c.weekends[t.Weekday()] && !c.holidays[t.Format(dateLayout)]
+}
+
+// WorkingDuration returns the working time between from and to
+func (c *Calendar) WorkingDuration(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+
+	from, to = from.In(c.loc), to.In(c.loc)
+	var total time.Duration
+	for day := midnight(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !c.IsWorkingDay(day) {
+			continue
+		}
+		start := clockTime(day, c.start)
+		end := clockTime(day, c.end)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// WorkingMinutes returns the working time between from and to in minutes
+func (c *Calendar) WorkingMinutes(from, to time.Time) float64 {
+	return c.WorkingDuration(from, to).Minutes()
+}
+
+// midnight returns the start of the day of t in its location
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// clockTime returns the wall clock time offset from the start of day, which
+// stays correct on days with a daylight saving time change
+func clockTime(day time.Time, offset time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, int(offset.Minutes()), 0, 0, day.Location())
+}
+
+// parseClock parses an HH:MM time of day
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// parseWeekday parses an English weekday name or its three-letter abbreviation
+func parseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", name)
+}
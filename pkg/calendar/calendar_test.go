@@ -0,0 +1,136 @@
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWorkingDuration(t *testing.T) {
+	holidays := filepath.Join(t.TempDir(), "holidays.txt")
+	if err := os.WriteFile(holidays, []byte("# International Women's Day\n2024-03-08\n"), 0o644); err != nil {
+		t.Fatalf("failed to write holidays file: %v", err)
+	}
+
+	office := Config{
+		TimeZone:  "Europe/Moscow",
+		WorkStart: "09:00",
+		WorkEnd:   "18:00",
+		Weekends:  []string{"saturday", "sunday"},
+	}
+	withHolidays := office
+	withHolidays.HolidaysFile = holidays
+	night := Config{
+		TimeZone:  "Europe/Berlin",
+		WorkStart: "00:00",
+		WorkEnd:   "06:00",
+	}
+	daytime := Config{
+		TimeZone:  "Europe/Berlin",
+		WorkStart: "09:00",
+		WorkEnd:   "18:00",
+	}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		from, to string // local time in the calendar time zone
+		want     time.Duration
+	}{
+		{
+			name: "within a working day",
+			cfg:  office,
+			from: "2024-03-04 10:00", to: "2024-03-04 12:30",
+			want: 2*time.Hour + 30*time.Minute,
+		},
+		{
+			name: "overnight",
+			cfg:  office,
+			from: "2024-03-04 17:00", to: "2024-03-05 10:00",
+			want: 2 * time.Hour,
+		},
+		{
+			name: "before working hours",
+			cfg:  office,
+			from: "2024-03-04 06:00", to: "2024-03-04 08:59",
+			want: 0,
+		},
+		{
+			name: "over a weekend",
+			cfg:  office,
+			from: "2024-03-08 17:00", to: "2024-03-11 10:00",
+			want: 2 * time.Hour,
+		},
+		{
+			name: "within a weekend",
+			cfg:  office,
+			from: "2024-03-09 10:00", to: "2024-03-10 18:00",
+			want: 0,
+		},
+		{
+			name: "over a holiday and a weekend",
+			cfg:  withHolidays,
+			from: "2024-03-07 17:00", to: "2024-03-11 10:00",
+			want: 2 * time.Hour,
+		},
+		{
+			name: "full working week",
+			cfg:  office,
+			from: "2024-03-04 00:00", to: "2024-03-11 00:00",
+			want: 45 * time.Hour,
+		},
+		{
+			name: "to before from",
+			cfg:  office,
+			from: "2024-03-04 12:00", to: "2024-03-04 10:00",
+			want: 0,
+		},
+		{
+			name: "daylight saving time starts",
+			cfg:  night,
+			from: "2024-03-31 00:00", to: "2024-03-31 12:00",
+			want: 5 * time.Hour,
+		},
+		{
+			name: "daylight saving time ends",
+			cfg:  night,
+			from: "2024-10-27 00:00", to: "2024-10-27 12:00",
+			want: 7 * time.Hour,
+		},
+		{
+			name: "working day keeps its wall clock hours across the change",
+			cfg:  daytime,
+			from: "2024-03-30 00:00", to: "2024-04-01 00:00",
+			want: 18 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cal, err := New(tt.cfg)
+			if err != nil {
+				t.Fatalf("failed to create calendar: %v", err)
+			}
+			from := parseLocal(t, tt.from, cal.Location())
+			to := parseLocal(t, tt.to, cal.Location())
+
+			if got := cal.WorkingDuration(from, to); got != tt.want {
+				t.Errorf("WorkingDuration(%s, %s) = %s, want %s", tt.from, tt.to, got, tt.want)
+			}
+			// The result does not depend on the time zone of the arguments
+			if got := cal.WorkingDuration(from.UTC(), to.UTC()); got != tt.want {
+				t.Errorf("WorkingDuration(%s, %s) in UTC = %s, want %s", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func parseLocal(t *testing.T, value string, loc *time.Location) time.Time {
+	t.Helper()
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		t.Fatalf("failed to parse time %q: %v", value, err)
+	}
+	return parsed
+}
@@ -1,10 +1,19 @@
 package logger
 
 import (
+	"io"
 	"log/slog"
 	"os"
 )
 
+// output is where logs are written
+var output io.Writer = os.Stdout
+
+// SetOutput changes where logs are written. It must be called before SetupLogging.
+func SetOutput(w io.Writer) {
+	output = w
+}
+
 func SetupLogging(appLogLevel string) {
 	var level slog.Level
 
@@ -21,7 +30,7 @@ func SetupLogging(appLogLevel string) {
 		level = slog.LevelInfo
 	}
 
-	logHandler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	logHandler := slog.NewJSONHandler(output, &slog.HandlerOptions{
 		Level: level,
 	})
 	logger := slog.New(logHandler)
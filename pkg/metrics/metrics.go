@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+const namespace = "tracker_import"
+
+// Registry holds all application metrics
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	// HTTPRequests counts Tracker API requests by endpoint and response status
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tracker",
+		Name:      "http_requests_total",
+		Help:      "Number of Tracker API requests by endpoint and status code.",
+	}, []string{"endpoint", "status"})
+
+	// HTTPRequestDuration observes Tracker API request latencies
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "tracker",
+		Name:      "http_request_duration_seconds",
+		Help:      "Tracker API request latency in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	// HTTPRetries counts retried Tracker API requests
+	HTTPRetries = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tracker",
+		Name:      "http_retries_total",
+		Help:      "Number of retried Tracker API requests by endpoint.",
+	}, []string{"endpoint"})
+
+	// HTTPRateLimited counts Tracker API responses with status 429
+	HTTPRateLimited = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tracker",
+		Name:      "http_rate_limited_total",
+		Help:      "Number of Tracker API responses with status 429 by endpoint.",
+	}, []string{"endpoint"})
+
+	// LimiterWait observes time spent waiting for the client-side rate limiter
+	LimiterWait = factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "tracker",
+		Name:      "limiter_wait_seconds",
+		Help:      "Time spent waiting for the client-side rate limiter in seconds.",
+		Buckets:   []float64{0.001, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30},
+	})
+
+	// RowsUpserted counts rows written to the database by table
+	RowsUpserted = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "rows_upserted_total",
+		Help:      "Number of rows inserted or updated by table.",
+	}, []string{"table"})
+
+	// SyncRuns counts finished sync runs by status
+	SyncRuns = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "runs_total",
+		Help:      "Number of finished sync runs by status.",
+	}, []string{"status"})
+
+	// SyncDuration holds the duration of the last sync run
+	SyncDuration = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "duration_seconds",
+		Help:      "Duration of the last sync run in seconds.",
+	})
+
+	// SyncLastSuccess holds the finish time of the last successful sync run
+	SyncLastSuccess = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "last_success_timestamp_seconds",
+		Help:      "Unix timestamp of the last successful sync run.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// ObserveSync records the outcome of a sync run
+func ObserveSync(status string, duration time.Duration, finishedAt time.Time, success bool) {
+	SyncRuns.WithLabelValues(status).Inc()
+	SyncDuration.Set(duration.Seconds())
+	if success {
+		SyncLastSuccess.Set(float64(finishedAt.Unix()))
+	}
+}
+
+// Handler returns the HTTP handler exposing the application metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Push sends the current metrics to a Prometheus Pushgateway
+func Push(ctx context.Context, url, job string) error {
+	if err := push.New(url, job).Gatherer(Registry).PushContext(ctx); err != nil {
+		return fmt.Errorf("failed to push metrics to %s: %w", url, err)
+	}
+	slog.Info("Pushed metrics to Pushgateway", "url", url, "job", job)
+	return nil
+}
@@ -0,0 +1,119 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/time/rate"
+)
+
+// Attachment represents a file attached to an issue
+type Attachment struct {
+	Self      string `json:"self"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Content   string `json:"content"`
+	Thumbnail string `json:"thumbnail"`
+	CreatedBy User   `json:"createdBy"`
+	CreatedAt Time   `json:"createdAt"`
+	Mimetype  string `json:"mimetype"`
+	Size      int64  `json:"size"`
+
+	// Additional fields for storage
+	OrganizationID string `json:"organization_id"`
+	IssueKey       string `json:"issue_key"`
+}
+
+// GetAttachmentsConcurrently retrieves attachment metadata of multiple issues
+// in parallel with rate limiting
+func (s *Service) GetAttachmentsConcurrently(ctx context.Context, issues []Issue, workers int) ([]Attachment, error) {
+	limiter := rate.NewLimiter(rate.Limit(20), 5)
+	keys := make(chan string)
+	results := make(chan []Attachment, len(issues))
+	errs := make(chan error, len(issues))
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				attachments, err := s.getIssueAttachments(ctx, limiter, key)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				results <- attachments
+			}
+		}()
+	}
+
+	for _, issue := range issues {
+		keys <- issue.Key
+	}
+	close(keys)
+	wg.Wait()
+	close(results)
+	close(errs)
+
+	// Check for errors
+	if err, ok := <-errs; ok {
+		return nil, err
+	}
+
+	var all []Attachment
+	for attachments := range results {
+		all = append(all, attachments...)
+	}
+
+	slog.Info("Finished fetching all attachments", "total_attachments", len(all))
+	return all, nil
+}
+
+// getIssueAttachments fetches the attachments of an issue, retrying failed requests
+func (s *Service) getIssueAttachments(ctx context.Context, limiter *rate.Limiter, issueKey string) ([]Attachment, error) {
+	var attachments []Attachment
+	path := "/issues/" + url.PathEscape(issueKey) + "/attachments"
+	if err := s.getJSONRetry(ctx, limiter, path, "attachments", &attachments, attribute.String("tracker.issue_key", issueKey)); err != nil {
+		return nil, err
+	}
+
+	for i := range attachments {
+		attachments[i].OrganizationID = s.cfg.Tracker.OrgID
+		attachments[i].IssueKey = issueKey
+	}
+	return attachments, nil
+}
+
+// DownloadAttachment streams the content of an attachment to w
+func (s *Service) DownloadAttachment(ctx context.Context, contentURL string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", contentURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("X-Org-ID", s.cfg.Tracker.OrgID)
+	req.Header.Set("Authorization", "OAuth "+s.cfg.Tracker.OAuthToken)
+
+	resp, err := s.doWith(s.downloadClient, req, "attachment_content")
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("tracker API error for %s: status=%d, body=%s", contentURL, resp.StatusCode, string(body))
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to read attachment content: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/time/rate"
+)
+
+// BoardInfo represents an agile board returned by the boards API
+type BoardInfo struct {
+	Self       string `json:"self"`
+	ID         int    `json:"id"`
+	Version    int    `json:"version"`
+	Name       string `json:"name"`
+	Query      string `json:"query"`
+	UseRanking bool   `json:"useRanking"`
+
+	// Additional fields for storage
+	OrganizationID string `json:"organization_id"`
+}
+
+// Sprint represents a sprint of an agile board
+type Sprint struct {
+	Self          string `json:"self"`
+	ID            int    `json:"id"`
+	Version       int    `json:"version"`
+	Name          string `json:"name"`
+	Board         Entity `json:"board"`
+	Status        string `json:"status"`
+	Archived      bool   `json:"archived"`
+	CreatedBy     User   `json:"createdBy"`
+	CreatedAt     Time   `json:"createdAt"`
+	StartDate     Time   `json:"startDate"`
+	EndDate       Time   `json:"endDate"`
+	StartDateTime Time   `json:"startDateTime"`
+	EndDateTime   Time   `json:"endDateTime"`
+
+	// Additional fields for storage
+	OrganizationID string `json:"organization_id"`
+	TrackerID      string `json:"tracker_id"`
+	BoardID        int    `json:"board_id"`
+}
+
+// GetBoards fetches all agile boards from the Tracker API
+func (s *Service) GetBoards(ctx context.Context) ([]BoardInfo, error) {
+	var boards []BoardInfo
+	if _, err := s.getJSON(ctx, "/boards", "boards", &boards); err != nil {
+		return nil, fmt.Errorf("failed to get boards: %w", err)
+	}
+
+	for i := range boards {
+		boards[i].OrganizationID = s.cfg.Tracker.OrgID
+	}
+
+	slog.Info("Successfully retrieved all boards", "total_boards", len(boards))
+	return boards, nil
+}
+
+// GetSprints fetches the sprints of the given boards from the Tracker API,
+// retrying failed requests
+func (s *Service) GetSprints(ctx context.Context, boards []BoardInfo) ([]Sprint, error) {
+	var sprints []Sprint
+	limiter := rate.NewLimiter(rate.Limit(20), 5)
+	for _, board := range boards {
+		var boardSprints []Sprint
+		path := "/boards/" + strconv.Itoa(board.ID) + "/sprints"
+		if err := s.getJSONRetry(ctx, limiter, path, "board_sprints", &boardSprints, attribute.Int("tracker.board_id", board.ID)); err != nil {
+			return nil, fmt.Errorf("failed to get sprints of board %d: %w", board.ID, err)
+		}
+
+		for i := range boardSprints {
+			boardSprints[i].OrganizationID = s.cfg.Tracker.OrgID
+			boardSprints[i].TrackerID = strconv.Itoa(boardSprints[i].ID)
+			boardSprints[i].BoardID = board.ID
+		}
+		sprints = append(sprints, boardSprints...)
+	}
+
+	slog.Info("Successfully retrieved all sprints", "total_sprints", len(sprints))
+	return sprints, nil
+}
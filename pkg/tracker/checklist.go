@@ -0,0 +1,40 @@
+package tracker
+
+import "encoding/json"
+
+// ChecklistItem represents an item of an issue checklist
+type ChecklistItem struct {
+	ID                string            `json:"id"`
+	Text              string            `json:"text"`
+	Checked           bool              `json:"checked"`
+	Assignee          ChecklistAssignee `json:"assignee"`
+	Deadline          ChecklistDeadline `json:"deadline"`
+	ChecklistItemType string            `json:"checklistItemType"`
+}
+
+// ChecklistAssignee represents the assignee of a checklist item. Unlike
+// other user references, the checklist API returns the ID as a number.
+type ChecklistAssignee struct {
+	ID          json.Number `json:"id"`
+	Display     string      `json:"display"`
+	Login       string      `json:"login"`
+	PassportUID int64       `json:"passportUid"`
+}
+
+// ChecklistDeadline represents the deadline of a checklist item
+type ChecklistDeadline struct {
+	Date         Time   `json:"date"`
+	DeadlineType string `json:"deadlineType"`
+	IsExceeded   bool   `json:"isExceeded"`
+}
+
+// fillChecklistCounts computes ChecklistDone and ChecklistTotal from the checklist items
+func (i *Issue) fillChecklistCounts() {
+	i.ChecklistTotal = len(i.ChecklistItems)
+	i.ChecklistDone = 0
+	for _, item := range i.ChecklistItems {
+		if item.Checked {
+			i.ChecklistDone++
+		}
+	}
+}
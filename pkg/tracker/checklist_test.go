@@ -0,0 +1,64 @@
+package tracker
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIssueDecodeChecklistAssignee(t *testing.T) {
+	tests := []struct {
+		name        string
+		assignee    string
+		wantID      string
+		wantDisplay string
+	}{
+		{
+			name:        "numeric id",
+			assignee:    `{"id": 1134669209, "display": "Ivan Ivanov", "passportUid": 1134669209, "login": "ivanov"}`,
+			wantID:      "1134669209",
+			wantDisplay: "Ivan Ivanov",
+		},
+		{
+			name:        "string id",
+			assignee:    `{"id": "1134669209", "display": "Ivan Ivanov"}`,
+			wantID:      "1134669209",
+			wantDisplay: "Ivan Ivanov",
+		},
+		{
+			name:     "no assignee",
+			assignee: `null`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := `[{"key": "TEST-1", "checklistItems": [{
+				"id": "5fde5f0a1aee261d00000001",
+				"text": "Check the release notes",
+				"checked": true,
+				"assignee": ` + tt.assignee + `,
+				"deadline": {"date": "2024-03-01T00:00:00.000+0000", "deadlineType": "date", "isExceeded": false},
+				"checklistItemType": "standard"
+			}]}]`
+
+			var issues []Issue
+			if err := json.Unmarshal([]byte(page), &issues); err != nil {
+				t.Fatalf("failed to decode issues: %v", err)
+			}
+			if len(issues) != 1 || len(issues[0].ChecklistItems) != 1 {
+				t.Fatalf("got %d issues, want 1 issue with 1 checklist item", len(issues))
+			}
+
+			item := issues[0].ChecklistItems[0]
+			if got := item.Assignee.ID.String(); got != tt.wantID {
+				t.Errorf("assignee ID = %q, want %q", got, tt.wantID)
+			}
+			if item.Assignee.Display != tt.wantDisplay {
+				t.Errorf("assignee display = %q, want %q", item.Assignee.Display, tt.wantDisplay)
+			}
+			if !item.Checked {
+				t.Error("item is not checked")
+			}
+		})
+	}
+}
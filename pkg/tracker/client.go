@@ -0,0 +1,139 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/time/rate"
+)
+
+// perPage is the page size used for paginated list endpoints
+const perPage = 100
+
+// getJSON sends a GET request to path and decodes the JSON response into out
+func (s *Service) getJSON(ctx context.Context, path, endpoint string, out interface{}, attrs ...attribute.KeyValue) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.cfg.Tracker.APIIssuesURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("X-Org-ID", s.cfg.Tracker.OrgID)
+	req.Header.Set("Authorization", "OAuth "+s.cfg.Tracker.OAuthToken)
+
+	resp, err := s.do(req, endpoint, attrs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("tracker API error for %s: status=%d, body=%s", path, resp.StatusCode, string(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("failed to decode response for %s: %w", path, err)
+	}
+
+	return resp.Header, nil
+}
+
+// getJSONRetry is like getJSON but waits for the rate limiter before every
+// attempt and retries failed requests
+func (s *Service) getJSONRetry(ctx context.Context, limiter *rate.Limiter, path, endpoint string, out interface{}, attrs ...attribute.KeyValue) error {
+	maxRetries := 3
+	retryDelay := 2 * time.Second
+
+	var lastErr error
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		if attempt > 1 {
+			metrics.HTTPRetries.WithLabelValues(endpoint).Inc()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retryDelay * time.Duration(attempt-1)):
+			}
+		}
+
+		// Wait for rate limiter
+		waitStart := time.Now()
+		if err := limiter.Wait(ctx); err != nil {
+			return fmt.Errorf("rate limiter error for %s: %w", path, err)
+		}
+		metrics.LimiterWait.Observe(time.Since(waitStart).Seconds())
+
+		_, err := s.getJSON(ctx, path, endpoint, out, append(attrs, attribute.Int("tracker.attempt", attempt))...)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("max retries exceeded for %s: %w", path, lastErr)
+}
+
+// postJSON sends body as JSON in a POST request to path and decodes the JSON response into out
+func (s *Service) postJSON(ctx context.Context, path, endpoint string, body, out interface{}, attrs ...attribute.KeyValue) (http.Header, error) {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.cfg.Tracker.APIIssuesURL+path, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("X-Org-ID", s.cfg.Tracker.OrgID)
+	req.Header.Set("Authorization", "OAuth "+s.cfg.Tracker.OAuthToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.do(req, endpoint, attrs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("tracker API error for %s: status=%d, body=%s", path, resp.StatusCode, string(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("failed to decode response for %s: %w", path, err)
+	}
+
+	return resp.Header, nil
+}
+
+// getAllPages fetches every page of a list endpoint paginated with page and
+// perPage parameters and the X-Total-Pages response header
+func getAllPages[T any](ctx context.Context, s *Service, path, endpoint string) ([]T, error) {
+	var items []T
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("perPage", strconv.Itoa(perPage))
+		query.Set("page", strconv.Itoa(page))
+
+		var pageItems []T
+		header, err := s.getJSON(ctx, path+"?"+query.Encode(), endpoint, &pageItems, attribute.Int("tracker.page", page))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, pageItems...)
+
+		totalPages, err := strconv.Atoi(header.Get("X-Total-Pages"))
+		if err != nil || page >= totalPages || len(pageItems) == 0 {
+			break
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,91 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// LocalizedName holds the translations of a name by language code. Names
+// returned as a plain string are stored under DefaultLanguage.
+type LocalizedName map[string]string
+
+// DefaultLanguage is the language key of names returned without translations
+const DefaultLanguage = "default"
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (n *LocalizedName) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*n = LocalizedName{DefaultLanguage: str}
+		return nil
+	}
+
+	var names map[string]string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("failed to decode localized name: %w", err)
+	}
+	*n = names
+	return nil
+}
+
+// Get returns the name in the given language, falling back to the default
+// language, Russian and English in that order
+func (n LocalizedName) Get(lang string) string {
+	for _, l := range []string{lang, DefaultLanguage, "ru", "en"} {
+		if name := n[l]; name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// DictionaryItem represents an issue type, priority or resolution
+type DictionaryItem struct {
+	Self        string        `json:"self"`
+	ID          int           `json:"id"`
+	Version     int           `json:"version"`
+	Key         string        `json:"key"`
+	Name        LocalizedName `json:"name"`
+	Description string        `json:"description"`
+	Order       int           `json:"order"`
+
+	// Additional fields for storage
+	OrganizationID string `json:"organization_id"`
+	TrackerID      string `json:"tracker_id"`
+}
+
+// GetIssueTypes fetches all issue types with all name translations
+func (s *Service) GetIssueTypes(ctx context.Context) ([]DictionaryItem, error) {
+	return s.getDictionary(ctx, "issuetypes")
+}
+
+// GetPriorities fetches all priorities with all name translations
+func (s *Service) GetPriorities(ctx context.Context) ([]DictionaryItem, error) {
+	return s.getDictionary(ctx, "priorities")
+}
+
+// GetResolutions fetches all resolutions with all name translations
+func (s *Service) GetResolutions(ctx context.Context) ([]DictionaryItem, error) {
+	return s.getDictionary(ctx, "resolutions")
+}
+
+// getDictionary fetches a dictionary endpoint with localized=false so that
+// names are returned with all translations
+func (s *Service) getDictionary(ctx context.Context, endpoint string) ([]DictionaryItem, error) {
+	var items []DictionaryItem
+	if _, err := s.getJSON(ctx, "/"+endpoint+"?localized=false", endpoint, &items); err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", endpoint, err)
+	}
+
+	for i := range items {
+		items[i].OrganizationID = s.cfg.Tracker.OrgID
+		items[i].TrackerID = strconv.Itoa(items[i].ID)
+	}
+	return items, nil
+}
@@ -0,0 +1,270 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/time/rate"
+)
+
+// Entity types supported by the entities API
+const (
+	EntityTypeProject   = "project"
+	EntityTypePortfolio = "portfolio"
+	EntityTypeGoal      = "goal"
+)
+
+// EntityTypes lists the entity types imported by GetEntities
+var EntityTypes = []string{EntityTypeProject, EntityTypePortfolio, EntityTypeGoal}
+
+// entityFields are the entity fields requested from the search endpoint
+var entityFields = []string{
+	"summary", "description", "entityStatus", "lead", "start", "end",
+	"parentEntity", "tags", "teamUsers", "followers", "clients",
+}
+
+// EntityInfo represents a project, portfolio or goal from the entities API
+type EntityInfo struct {
+	ID         string       `json:"id"`
+	ShortID    int          `json:"shortId"`
+	EntityType string       `json:"entityType"`
+	CreatedBy  User         `json:"createdBy"`
+	CreatedAt  Time         `json:"createdAt"`
+	UpdatedAt  Time         `json:"updatedAt"`
+	Fields     EntityFields `json:"fields"`
+
+	// Additional fields for storage
+	OrganizationID string               `json:"organization_id"`
+	Links          []EntityLink         `json:"links"`
+	StatusHistory  []EntityStatusChange `json:"status_history"`
+}
+
+// EntityFields holds the requested fields of an entity
+type EntityFields struct {
+	Summary      string     `json:"summary"`
+	Description  string     `json:"description"`
+	EntityStatus string     `json:"entityStatus"`
+	Lead         User       `json:"lead"`
+	Start        EntityDate `json:"start"`
+	End          EntityDate `json:"end"`
+	ParentEntity EntityRef  `json:"parentEntity"`
+	Tags         []string   `json:"tags"`
+
+	// Raw keeps every returned field for storage as JSON
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the known fields and keeps the raw object
+func (f *EntityFields) UnmarshalJSON(data []byte) error {
+	type plain EntityFields
+	if err := json.Unmarshal(data, (*plain)(f)); err != nil {
+		return err
+	}
+	f.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// EntityDate is an entity start or end date, returned either as a date string
+// or as an object with a date and its precision
+type EntityDate struct {
+	Date     Time   `json:"date"`
+	DateType string `json:"dateType"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (d *EntityDate) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return d.Date.UnmarshalJSON(data)
+	}
+	type plain EntityDate
+	return json.Unmarshal(data, (*plain)(d))
+}
+
+// EntityRef references another entity or an issue
+type EntityRef struct {
+	ID         string `json:"id"`
+	ShortID    int    `json:"shortId"`
+	Key        string `json:"key"`
+	EntityType string `json:"entityType"`
+	Display    string `json:"display"`
+}
+
+// EntityLink is a link from an entity to another entity or an issue
+type EntityLink struct {
+	Type struct {
+		ID      string `json:"id"`
+		Inward  string `json:"inward"`
+		Outward string `json:"outward"`
+	} `json:"type"`
+	Direction    string    `json:"direction"`
+	LinkedEntity EntityRef `json:"linkedEntity"`
+}
+
+// EntityStatusChange is a change of the entity status
+type EntityStatusChange struct {
+	EventID   string
+	Author    User
+	ChangedAt Time
+	From      string
+	To        string
+}
+
+// entityEvent is an event from the entity history
+type entityEvent struct {
+	ID      string `json:"id"`
+	Author  User   `json:"author"`
+	Date    Time   `json:"date"`
+	Changes []struct {
+		Field Field `json:"field"`
+		Diff  struct {
+			OldValue json.RawMessage `json:"oldValue"`
+			NewValue json.RawMessage `json:"newValue"`
+		} `json:"diff"`
+	} `json:"changes"`
+}
+
+// GetEntities fetches the projects, portfolios and goals of the organization
+// together with their links and status history. The per-entity requests are
+// rate limited and retried.
+func (s *Service) GetEntities(ctx context.Context) ([]EntityInfo, error) {
+	limiter := rate.NewLimiter(rate.Limit(20), 5)
+
+	var entities []EntityInfo
+	for _, entityType := range EntityTypes {
+		items, err := s.searchEntities(ctx, entityType)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range items {
+			items[i].OrganizationID = s.cfg.Tracker.OrgID
+			if items[i].EntityType == "" {
+				items[i].EntityType = entityType
+			}
+			if items[i].Links, err = s.getEntityLinks(ctx, limiter, entityType, items[i].ID); err != nil {
+				return nil, err
+			}
+			if items[i].StatusHistory, err = s.getEntityStatusHistory(ctx, limiter, entityType, items[i].ID); err != nil {
+				return nil, err
+			}
+		}
+		entities = append(entities, items...)
+	}
+
+	slog.Info("Successfully retrieved all entities", "total_entities", len(entities))
+	return entities, nil
+}
+
+// searchEntities fetches every page of the entity search results of a type
+func (s *Service) searchEntities(ctx context.Context, entityType string) ([]EntityInfo, error) {
+	path := "/entities/" + entityType + "/_search"
+
+	var entities []EntityInfo
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("fields", strings.Join(entityFields, ","))
+		query.Set("perPage", strconv.Itoa(perPage))
+		query.Set("page", strconv.Itoa(page))
+
+		var resp struct {
+			Hits   int          `json:"hits"`
+			Pages  int          `json:"pages"`
+			Values []EntityInfo `json:"values"`
+		}
+		_, err := s.postJSON(ctx, path+"?"+query.Encode(), "entities_search", struct{}{}, &resp,
+			attribute.String("tracker.entity_type", entityType),
+			attribute.Int("tracker.page", page),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %s entities: %w", entityType, err)
+		}
+		entities = append(entities, resp.Values...)
+
+		if page >= resp.Pages || len(resp.Values) == 0 {
+			break
+		}
+	}
+	return entities, nil
+}
+
+// getEntityLinks fetches the links of an entity
+func (s *Service) getEntityLinks(ctx context.Context, limiter *rate.Limiter, entityType, id string) ([]EntityLink, error) {
+	path := "/entities/" + entityType + "/" + url.PathEscape(id) + "/links?fields=summary,entityStatus"
+
+	var links []EntityLink
+	if err := s.getJSONRetry(ctx, limiter, path, "entity_links", &links, attribute.String("tracker.entity_id", id)); err != nil {
+		return nil, fmt.Errorf("failed to get links of %s %s: %w", entityType, id, err)
+	}
+	return links, nil
+}
+
+// getEntityStatusHistory walks the event history of an entity and returns
+// its status changes in chronological order
+func (s *Service) getEntityStatusHistory(ctx context.Context, limiter *rate.Limiter, entityType, id string) ([]EntityStatusChange, error) {
+	path := "/entities/" + entityType + "/" + url.PathEscape(id) + "/events/_relative"
+
+	var changes []EntityStatusChange
+	from := ""
+	for {
+		query := url.Values{}
+		query.Set("perPage", strconv.Itoa(perPage))
+		query.Set("direction", "forward")
+		if from != "" {
+			query.Set("from", from)
+		}
+
+		var resp struct {
+			Events  []entityEvent `json:"events"`
+			HasNext bool          `json:"hasNext"`
+		}
+		err := s.getJSONRetry(ctx, limiter, path+"?"+query.Encode(), "entity_events", &resp,
+			attribute.String("tracker.entity_id", id))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get events of %s %s: %w", entityType, id, err)
+		}
+
+		for _, event := range resp.Events {
+			for _, change := range event.Changes {
+				if change.Field.ID != "entityStatus" {
+					continue
+				}
+				changes = append(changes, EntityStatusChange{
+					EventID:   event.ID,
+					Author:    event.Author,
+					ChangedAt: event.Date,
+					From:      entityStatusValue(change.Diff.OldValue),
+					To:        entityStatusValue(change.Diff.NewValue),
+				})
+			}
+		}
+
+		if !resp.HasNext || len(resp.Events) == 0 {
+			break
+		}
+		from = resp.Events[len(resp.Events)-1].ID
+	}
+	return changes, nil
+}
+
+// entityStatusValue decodes a status from an event diff, given either as a
+// plain string or as an object with a key or ID
+func entityStatusValue(raw json.RawMessage) string {
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str
+	}
+	var field Field
+	if err := json.Unmarshal(raw, &field); err == nil {
+		if field.Key != "" {
+			return field.Key
+		}
+		return field.ID
+	}
+	return ""
+}
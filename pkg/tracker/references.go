@@ -0,0 +1,196 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/time/rate"
+)
+
+// Queue represents a Tracker queue
+type Queue struct {
+	Self            string `json:"self"`
+	ID              int    `json:"id"`
+	Version         int    `json:"version"`
+	Key             string `json:"key"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Lead            User   `json:"lead"`
+	AssignAuto      bool   `json:"assignAuto"`
+	DefaultType     Entity `json:"defaultType"`
+	DefaultPriority Entity `json:"defaultPriority"`
+	DenyVoting      bool   `json:"denyVoting"`
+
+	// Additional fields for storage
+	OrganizationID string `json:"organization_id"`
+	TrackerID      string `json:"tracker_id"`
+}
+
+// Project represents a Tracker project
+type Project struct {
+	Self        string `json:"self"`
+	ID          int    `json:"id"`
+	Version     int    `json:"version"`
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Lead        User   `json:"lead"`
+	Status      string `json:"status"`
+	StartDate   Time   `json:"startDate"`
+	EndDate     Time   `json:"endDate"`
+
+	// Additional fields for storage
+	OrganizationID string `json:"organization_id"`
+	TrackerID      string `json:"tracker_id"`
+}
+
+// Component represents a queue component
+type Component struct {
+	Self        string `json:"self"`
+	ID          int    `json:"id"`
+	Version     int    `json:"version"`
+	Name        string `json:"name"`
+	Queue       Entity `json:"queue"`
+	Description string `json:"description"`
+	Lead        User   `json:"lead"`
+	AssignAuto  bool   `json:"assignAuto"`
+
+	// Additional fields for storage
+	OrganizationID string `json:"organization_id"`
+	TrackerID      string `json:"tracker_id"`
+}
+
+// Version represents a queue version
+type Version struct {
+	Self        string `json:"self"`
+	ID          int    `json:"id"`
+	Version     int    `json:"version"`
+	Name        string `json:"name"`
+	Queue       Entity `json:"queue"`
+	Description string `json:"description"`
+	StartDate   Time   `json:"startDate"`
+	DueDate     Time   `json:"dueDate"`
+	Released    bool   `json:"released"`
+	Archived    bool   `json:"archived"`
+
+	// Additional fields for storage
+	OrganizationID string `json:"organization_id"`
+	TrackerID      string `json:"tracker_id"`
+}
+
+// References holds the reference data issues point to
+type References struct {
+	Queues      []Queue
+	Projects    []Project
+	Components  []Component
+	Versions    []Version
+	IssueTypes  []DictionaryItem
+	Priorities  []DictionaryItem
+	Resolutions []DictionaryItem
+}
+
+// GetReferences fetches queues, projects, components, versions, issue types,
+// priorities and resolutions from the Tracker API
+func (s *Service) GetReferences(ctx context.Context) (*References, error) {
+	var refs References
+	var err error
+
+	if refs.Queues, err = s.GetQueues(ctx); err != nil {
+		return nil, err
+	}
+	if refs.Projects, err = s.GetProjects(ctx); err != nil {
+		return nil, err
+	}
+	if refs.Components, err = s.GetComponents(ctx); err != nil {
+		return nil, err
+	}
+	limiter := rate.NewLimiter(rate.Limit(20), 5)
+	for _, q := range refs.Queues {
+		versions, err := s.GetQueueVersions(ctx, limiter, q.Key)
+		if err != nil {
+			return nil, err
+		}
+		refs.Versions = append(refs.Versions, versions...)
+	}
+	if refs.IssueTypes, err = s.GetIssueTypes(ctx); err != nil {
+		return nil, err
+	}
+	if refs.Priorities, err = s.GetPriorities(ctx); err != nil {
+		return nil, err
+	}
+	if refs.Resolutions, err = s.GetResolutions(ctx); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Successfully retrieved reference data",
+		"queues", len(refs.Queues),
+		"projects", len(refs.Projects),
+		"components", len(refs.Components),
+		"versions", len(refs.Versions),
+		"issue_types", len(refs.IssueTypes),
+		"priorities", len(refs.Priorities),
+		"resolutions", len(refs.Resolutions))
+	return &refs, nil
+}
+
+// GetQueues fetches all queues from the Tracker API
+func (s *Service) GetQueues(ctx context.Context) ([]Queue, error) {
+	queues, err := getAllPages[Queue](ctx, s, "/queues", "queues")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queues: %w", err)
+	}
+
+	for i := range queues {
+		queues[i].OrganizationID = s.cfg.Tracker.OrgID
+		queues[i].TrackerID = strconv.Itoa(queues[i].ID)
+	}
+	return queues, nil
+}
+
+// GetProjects fetches all projects from the Tracker API
+func (s *Service) GetProjects(ctx context.Context) ([]Project, error) {
+	projects, err := getAllPages[Project](ctx, s, "/projects", "projects")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+
+	for i := range projects {
+		projects[i].OrganizationID = s.cfg.Tracker.OrgID
+		projects[i].TrackerID = strconv.Itoa(projects[i].ID)
+	}
+	return projects, nil
+}
+
+// GetComponents fetches the components of all queues from the Tracker API
+func (s *Service) GetComponents(ctx context.Context) ([]Component, error) {
+	var components []Component
+	if _, err := s.getJSON(ctx, "/components", "components", &components); err != nil {
+		return nil, fmt.Errorf("failed to get components: %w", err)
+	}
+
+	for i := range components {
+		components[i].OrganizationID = s.cfg.Tracker.OrgID
+		components[i].TrackerID = strconv.Itoa(components[i].ID)
+	}
+	return components, nil
+}
+
+// GetQueueVersions fetches the versions of a queue from the Tracker API,
+// retrying failed requests
+func (s *Service) GetQueueVersions(ctx context.Context, limiter *rate.Limiter, queueKey string) ([]Version, error) {
+	var versions []Version
+	path := "/queues/" + url.PathEscape(queueKey) + "/versions"
+	if err := s.getJSONRetry(ctx, limiter, path, "queue_versions", &versions, attribute.String("tracker.queue", queueKey)); err != nil {
+		return nil, fmt.Errorf("failed to get versions of queue %s: %w", queueKey, err)
+	}
+
+	for i := range versions {
+		versions[i].OrganizationID = s.cfg.Tracker.OrgID
+		versions[i].TrackerID = strconv.Itoa(versions[i].ID)
+	}
+	return versions, nil
+}
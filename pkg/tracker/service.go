@@ -3,22 +3,33 @@ package tracker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/nemirlev/yc-tracker-go-data-import/internal/config"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/metrics"
+	"github.com/nemirlev/yc-tracker-go-data-import/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"golang.org/x/time/rate"
 )
 
 type Service struct {
-	cfg    *config.Config
-	client *http.Client
+	cfg      *config.Config
+	client   *http.Client
+	apiCalls atomic.Int64
+
+	// downloadClient has a longer timeout so that large attachments can be downloaded
+	downloadClient *http.Client
 }
 
 func NewService(cfg *config.Config) *Service {
@@ -27,9 +38,56 @@ func NewService(cfg *config.Config) *Service {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		downloadClient: &http.Client{
+			Timeout: 30 * time.Minute,
+		},
 	}
 }
 
+// APICalls returns the number of requests sent to the Tracker API so far
+func (s *Service) APICalls() int64 {
+	return s.apiCalls.Load()
+}
+
+// do sends an HTTP request to the Tracker API, counts it and records metrics
+// under the given endpoint label. The request is traced as a span carrying attrs.
+func (s *Service) do(req *http.Request, endpoint string, attrs ...attribute.KeyValue) (*http.Response, error) {
+	return s.doWith(s.client, req, endpoint, attrs...)
+}
+
+// doWith is like do but sends the request with the given client
+func (s *Service) doWith(client *http.Client, req *http.Request, endpoint string, attrs ...attribute.KeyValue) (*http.Response, error) {
+	s.apiCalls.Add(1)
+
+	attrs = append(attrs,
+		attribute.String("tracker.endpoint", endpoint),
+		attribute.String("http.request.method", req.Method),
+	)
+	ctx, span := tracing.Start(req.Context(), "tracker "+endpoint, attrs...)
+	defer span.End()
+
+	start := time.Now()
+	resp, err := client.Do(req.WithContext(ctx))
+	metrics.HTTPRequestDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.HTTPRequests.WithLabelValues(endpoint, "error").Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+
+	metrics.HTTPRequests.WithLabelValues(endpoint, strconv.Itoa(resp.StatusCode)).Inc()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		metrics.HTTPRateLimited.WithLabelValues(endpoint).Inc()
+	}
+	return resp, nil
+}
+
 // Time represents a custom time type that can handle various time formats
 type Time time.Time
 
@@ -147,9 +205,12 @@ type Issue struct {
 	Transitions                        []Entity `json:"transitions"`
 	Favorite                           bool     `json:"favorite"`
 
+	ChecklistItems []ChecklistItem `json:"checklistItems"`
+	SLA            []SLATimer      `json:"sla"`
+
 	// Additional fields for storage
 	OrganizationID     string   `json:"organization_id"`
-	StoryPoints        float64  `json:"story_points"`
+	StoryPoints        float64  `json:"storyPoints"`
 	BoardsNames        string   `json:"boards_names"`
 	Deadline           Time     `json:"deadline"`
 	Parent             Entity   `json:"parent"`
@@ -161,7 +222,6 @@ type Issue struct {
 	ChecklistDone      int      `json:"checklist_done"`
 	ChecklistTotal     int      `json:"checklist_total"`
 	EmailCreatedBy     string   `json:"email_created_by"`
-	SLA                []Entity `json:"sla"`
 	EmailTo            string   `json:"email_to"`
 	EmailFrom          string   `json:"email_from"`
 	PendingReplyFrom   string   `json:"pending_reply_from"`
@@ -177,9 +237,15 @@ type Changelog struct {
 	IssueKey         string `json:"issueKey"`
 	UpdatedAt        Time   `json:"updatedAt"`
 	UpdatedByDisplay string `json:"updatedBy_display"`
+	UpdatedByID      string `json:"updatedBy_id"`
 	Type             string `json:"type"`
+	FieldID          string `json:"field_id"`
 	FieldDisplay     string `json:"field_display"`
+	FromID           string `json:"from_id"`
+	FromKey          string `json:"from_key"`
 	FromDisplay      string `json:"from_display"`
+	ToID             string `json:"to_id"`
+	ToKey            string `json:"to_key"`
 	ToDisplay        string `json:"to_display"`
 	Worklog          string `json:"worklog"`
 }
@@ -368,11 +434,13 @@ type ChangelogEntry struct {
 	} `json:"issue"`
 	UpdatedAt Time `json:"updatedAt"`
 	UpdatedBy struct {
+		ID      string `json:"id"`
 		Display string `json:"display"`
 	} `json:"updatedBy"`
 	Type   string `json:"type"`
 	Fields []struct {
 		Field struct {
+			ID      string `json:"id"`
 			Display string `json:"display"`
 		} `json:"field"`
 		From interface{} `json:"from"`
@@ -405,7 +473,7 @@ func (s *Service) GetStatusTypes(ctx context.Context) ([]StatusType, error) {
 	req.Header.Set("Authorization", fmt.Sprintf("OAuth %s", s.cfg.Tracker.OAuthToken))
 	req.Header.Set("X-Org-ID", s.cfg.Tracker.OrgID)
 
-	resp, err := s.client.Do(req)
+	resp, err := s.do(req, "statuses")
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -430,6 +498,62 @@ func (s *Service) GetStatusTypes(ctx context.Context) ([]StatusType, error) {
 	return statusTypes, nil
 }
 
+// GetMyself returns the user the OAuth token belongs to. It is a cheap call
+// used to check that the token is valid.
+func (s *Service) GetMyself(ctx context.Context) (*User, error) {
+	url := fmt.Sprintf("%s/myself", s.cfg.Tracker.APIIssuesURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("OAuth %s", s.cfg.Tracker.OAuthToken))
+	req.Header.Set("X-Org-ID", s.cfg.Tracker.OrgID)
+
+	resp, err := s.do(req, "myself")
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var user User
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &user, nil
+}
+
+// Ping checks that the OAuth token is valid. Unlike GetMyself the request is
+// sent directly with the client, so health probes do not show up in APICalls.
+func (s *Service) Ping(ctx context.Context) error {
+	url := fmt.Sprintf("%s/myself", s.cfg.Tracker.APIIssuesURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("OAuth %s", s.cfg.Tracker.OAuthToken))
+	req.Header.Set("X-Org-ID", s.cfg.Tracker.OrgID)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
 // GetIssuesCount returns the number of issues matching the filter
 func (s *Service) GetIssuesCount(ctx context.Context, query string) (int, error) {
 	url := fmt.Sprintf("%s/issues/_count", s.cfg.Tracker.APIIssuesURL)
@@ -449,7 +573,7 @@ func (s *Service) GetIssuesCount(ctx context.Context, query string) (int, error)
 	req.Header.Set("Authorization", "OAuth "+s.cfg.Tracker.OAuthToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.client.Do(req)
+	resp, err := s.do(req, "issues_count")
 	if err != nil {
 		return 0, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -503,7 +627,7 @@ func (s *Service) GetIssues(ctx context.Context, query string) ([]Issue, error)
 	req.Header.Set("Authorization", "OAuth "+s.cfg.Tracker.OAuthToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.client.Do(req)
+	resp, err := s.do(req, "issues_search", attribute.Int("tracker.scroll_page", 1))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -530,7 +654,7 @@ func (s *Service) GetIssues(ctx context.Context, query string) ([]Issue, error)
 		"total_count", totalCount)
 
 	// Continue fetching if we haven't got all records
-	for len(issues) < totalCount {
+	for page := 2; len(issues) < totalCount; page++ {
 		scrollID := resp.Header.Get("X-Scroll-Id")
 		scrollToken := resp.Header.Get("X-Scroll-Token")
 		scrollURL := fmt.Sprintf("%s/issues/_search?scrollId=%s&scrollToken=%s", s.cfg.Tracker.APIIssuesURL, scrollID, scrollToken)
@@ -544,7 +668,7 @@ func (s *Service) GetIssues(ctx context.Context, query string) ([]Issue, error)
 		req.Header.Set("Authorization", "OAuth "+s.cfg.Tracker.OAuthToken)
 		req.Header.Set("Content-Type", "application/json")
 
-		resp, err = s.client.Do(req)
+		resp, err = s.do(req, "issues_search", attribute.Int("tracker.scroll_page", page))
 		if err != nil {
 			return nil, fmt.Errorf("failed to send scroll request: %w", err)
 		}
@@ -566,12 +690,162 @@ func (s *Service) GetIssues(ctx context.Context, query string) ([]Issue, error)
 			"total_count", totalCount)
 	}
 
+	for i := range issues {
+		issues[i].fillChecklistCounts()
+	}
+
 	slog.Info("Successfully retrieved all issues",
 		"total_issues", len(issues),
 		"query", query)
 	return issues, nil
 }
 
+// ErrIssueNotFound is returned when an issue does not exist or is not accessible
+var ErrIssueNotFound = errors.New("issue not found")
+
+// IssueRef identifies an issue by its tracker ID and key
+type IssueRef struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+// GetIssueRefs retrieves IDs and keys of all issues matching the query using
+// scroll API. Unlike GetIssues it ignores the initial history depth.
+func (s *Service) GetIssueRefs(ctx context.Context, query string) ([]IssueRef, error) {
+	slog.Info("Starting getting issue refs", "query", query)
+
+	reqBody := map[string]string{"query": query}
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/issues/_search?scrollType=unsorted&perScroll=1000&scrollTTLMillis=60000&fields=id,key", s.cfg.Tracker.APIIssuesURL)
+
+	var refs []IssueRef
+	totalCount := -1
+	for page := 1; totalCount < 0 || len(refs) < totalCount; page++ {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(bodyBytes)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("X-Org-ID", s.cfg.Tracker.OrgID)
+		req.Header.Set("Authorization", "OAuth "+s.cfg.Tracker.OAuthToken)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := s.do(req, "issues_search_refs", attribute.Int("tracker.scroll_page", page))
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("tracker API error: status=%d, body=%s", resp.StatusCode, string(body))
+		}
+
+		var pageRefs []IssueRef
+		if err := json.NewDecoder(resp.Body).Decode(&pageRefs); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		resp.Body.Close()
+
+		if totalCount < 0 {
+			totalCount, err = strconv.Atoi(resp.Header.Get("X-Total-Count"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse total count: %w", err)
+			}
+		}
+
+		refs = append(refs, pageRefs...)
+		if len(pageRefs) == 0 {
+			break
+		}
+
+		url = fmt.Sprintf("%s/issues/_search?scrollId=%s&scrollToken=%s&fields=id,key",
+			s.cfg.Tracker.APIIssuesURL, resp.Header.Get("X-Scroll-Id"), resp.Header.Get("X-Scroll-Token"))
+	}
+
+	if len(refs) < totalCount {
+		return nil, fmt.Errorf("scroll ended early: got %d of %d issues", len(refs), totalCount)
+	}
+
+	slog.Info("Successfully retrieved all issue refs", "total_issues", len(refs))
+	return refs, nil
+}
+
+// GetIssue retrieves a single issue by key. ErrIssueNotFound is returned when
+// the issue was deleted or the token has no access to it. Requests wait on
+// limiter and are retried on transport errors, 429 and 5xx responses.
+func (s *Service) GetIssue(ctx context.Context, limiter *rate.Limiter, key string) (*Issue, error) {
+	maxRetries := 3
+	retryDelay := 2 * time.Second
+	url := fmt.Sprintf("%s/issues/%s", s.cfg.Tracker.APIIssuesURL, key)
+
+	var lastErr error
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		if attempt > 1 {
+			metrics.HTTPRetries.WithLabelValues("issue").Inc()
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(retryDelay * time.Duration(attempt-1)):
+			}
+		}
+
+		// Wait for rate limiter
+		waitStart := time.Now()
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limiter error for issue %s: %w", key, err)
+		}
+		metrics.LimiterWait.Observe(time.Since(waitStart).Seconds())
+
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("X-Org-ID", s.cfg.Tracker.OrgID)
+		req.Header.Set("Authorization", "OAuth "+s.cfg.Tracker.OAuthToken)
+
+		resp, err := s.do(req, "issue",
+			attribute.String("tracker.issue_key", key),
+			attribute.Int("tracker.attempt", attempt),
+		)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to send request for %s: %w", key, err)
+			continue
+		}
+
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%w: %s", ErrIssueNotFound, key)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			lastErr = fmt.Errorf("tracker API error for %s: status=%d, body=%s", key, resp.StatusCode, string(body))
+			if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+				continue
+			}
+			return nil, lastErr
+		}
+
+		var issue Issue
+		err = json.NewDecoder(resp.Body).Decode(&issue)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response for %s: %w", key, err)
+		}
+		issue.fillChecklistCounts()
+
+		return &issue, nil
+	}
+	return nil, fmt.Errorf("max retries exceeded for issue %s: %w", key, lastErr)
+}
+
 // GetChangelogsConcurrently retrieves changelogs for multiple issues in parallel with rate limiting
 func (s *Service) GetChangelogsConcurrently(ctx context.Context, issues []Issue) ([]Changelog, error) {
 	var wg sync.WaitGroup
@@ -604,61 +878,19 @@ func (s *Service) GetChangelogsConcurrently(ctx context.Context, issues []Issue)
 			defer wg.Done()
 			defer func() { progressChan <- issueKey }()
 
-			maxRetries := 3
-			retryDelay := 2 * time.Second
-			var lastErr error
-
-			for attempt := 1; attempt <= maxRetries; attempt++ {
-				// Wait for rate limiter
-				if err := limiter.Wait(ctx); err != nil {
-					lastErr = fmt.Errorf("rate limiter error for issue %s: %w", issueKey, err)
-					continue
-				}
-
-				// Fetch changelog for the issue
-				url := fmt.Sprintf("%s/issues/%s/changelog?type=IssueWorkflow", s.cfg.Tracker.APIIssuesURL, issueKey)
-
-				req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-				if err != nil {
-					lastErr = fmt.Errorf("failed to create request for %s: %w", issueKey, err)
-					continue
-				}
-
-				req.Header.Set("X-Org-ID", s.cfg.Tracker.OrgID)
-				req.Header.Set("Authorization", "OAuth "+s.cfg.Tracker.OAuthToken)
+			// Fetch changes of all types, page by page
+			query := url.Values{}
+			query.Set("perPage", strconv.Itoa(perPage))
+			pageURL := fmt.Sprintf("%s/issues/%s/changelog?%s", s.cfg.Tracker.APIIssuesURL, issueKey, query.Encode())
 
-				resp, err := s.client.Do(req)
+			var changelogs []Changelog
+			for pageURL != "" {
+				entries, next, err := s.getChangelogPage(ctx, limiter, issueKey, pageURL)
 				if err != nil {
-					lastErr = fmt.Errorf("failed to send request for %s: %w", issueKey, err)
-					continue
-				}
-
-				if resp.StatusCode == http.StatusTooManyRequests {
-					resp.Body.Close()
-					slog.Warn("Rate limit exceeded, retrying",
-						"issue_key", issueKey,
-						"attempt", attempt,
-						"max_retries", maxRetries)
-					time.Sleep(retryDelay * time.Duration(attempt))
-					continue
-				}
-
-				if resp.StatusCode != http.StatusOK {
-					body, _ := io.ReadAll(resp.Body)
-					resp.Body.Close()
-					lastErr = fmt.Errorf("tracker API error for %s: status=%d, body=%s", issueKey, resp.StatusCode, string(body))
-					continue
+					errChan <- err
+					return
 				}
 
-				var entries []ChangelogEntry
-				if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
-					resp.Body.Close()
-					lastErr = fmt.Errorf("failed to decode response for %s: %w", issueKey, err)
-					continue
-				}
-				resp.Body.Close()
-
-				var changelogs []Changelog
 				for _, entry := range entries {
 					for _, field := range entry.Fields {
 						// Skip if field is empty
@@ -671,21 +903,25 @@ func (s *Service) GetChangelogsConcurrently(ctx context.Context, issues []Issue)
 							IssueKey:         entry.Issue.Key,
 							UpdatedAt:        entry.UpdatedAt,
 							UpdatedByDisplay: entry.UpdatedBy.Display,
+							UpdatedByID:      entry.UpdatedBy.ID,
 							Type:             entry.Type,
+							FieldID:          field.Field.ID,
 							FieldDisplay:     field.Field.Display,
+							FromID:           getAttrValue(field.From, "id"),
+							FromKey:          getAttrValue(field.From, "key"),
 							FromDisplay:      getDisplayValue(field.From),
+							ToID:             getAttrValue(field.To, "id"),
+							ToKey:            getAttrValue(field.To, "key"),
 							ToDisplay:        getDisplayValue(field.To),
 							Worklog:          "",
 						}
 						changelogs = append(changelogs, changelog)
 					}
 				}
-
-				changelogChan <- changelogs
-				return
+				pageURL = next
 			}
 
-			errChan <- fmt.Errorf("max retries exceeded for issue %s: %w", issueKey, lastErr)
+			changelogChan <- changelogs
 		}(issue.Key)
 	}
 
@@ -712,6 +948,94 @@ func (s *Service) GetChangelogsConcurrently(ctx context.Context, issues []Issue)
 	return allChangelogs, nil
 }
 
+// getChangelogPage fetches one page of an issue changelog, retrying rate
+// limited and failed requests. It returns the URL of the next page, if any.
+func (s *Service) getChangelogPage(ctx context.Context, limiter *rate.Limiter, issueKey, pageURL string) ([]ChangelogEntry, string, error) {
+	maxRetries := 3
+	retryDelay := 2 * time.Second
+	var lastErr error
+
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		if attempt > 1 {
+			metrics.HTTPRetries.WithLabelValues("changelog").Inc()
+		}
+
+		// Wait for rate limiter
+		waitStart := time.Now()
+		err := limiter.Wait(ctx)
+		metrics.LimiterWait.Observe(time.Since(waitStart).Seconds())
+		if err != nil {
+			lastErr = fmt.Errorf("rate limiter error for issue %s: %w", issueKey, err)
+			continue
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to create request for %s: %w", issueKey, err)
+			continue
+		}
+
+		req.Header.Set("X-Org-ID", s.cfg.Tracker.OrgID)
+		req.Header.Set("Authorization", "OAuth "+s.cfg.Tracker.OAuthToken)
+
+		resp, err := s.do(req, "changelog",
+			attribute.String("tracker.issue_key", issueKey),
+			attribute.Int("tracker.attempt", attempt),
+		)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to send request for %s: %w", issueKey, err)
+			continue
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			resp.Body.Close()
+			slog.Warn("Rate limit exceeded, retrying",
+				"issue_key", issueKey,
+				"attempt", attempt,
+				"max_retries", maxRetries)
+			time.Sleep(retryDelay * time.Duration(attempt))
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			lastErr = fmt.Errorf("tracker API error for %s: status=%d, body=%s", issueKey, resp.StatusCode, string(body))
+			continue
+		}
+
+		var entries []ChangelogEntry
+		if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("failed to decode response for %s: %w", issueKey, err)
+			continue
+		}
+		resp.Body.Close()
+
+		return entries, nextPageURL(resp.Header), nil
+	}
+
+	return nil, "", fmt.Errorf("max retries exceeded for issue %s: %w", issueKey, lastErr)
+}
+
+// nextPageURL returns the rel="next" URL of a Link response header
+func nextPageURL(header http.Header) string {
+	for _, link := range header.Values("Link") {
+		for _, part := range strings.Split(link, ",") {
+			segments := strings.Split(part, ";")
+			if len(segments) < 2 {
+				continue
+			}
+			for _, param := range segments[1:] {
+				if strings.ReplaceAll(strings.TrimSpace(param), `"`, "") == "rel=next" {
+					return strings.Trim(strings.TrimSpace(segments[0]), "<>")
+				}
+			}
+		}
+	}
+	return ""
+}
+
 // getDisplayValue extracts the display value from various types
 func getDisplayValue(v interface{}) string {
 	if v == nil {
@@ -738,3 +1062,34 @@ func getDisplayValue(v interface{}) string {
 	}
 	return fmt.Sprintf("%v", v)
 }
+
+// getAttrValue extracts an attribute such as "id" or "key" from an entity or
+// a list of entities. Scalar values have no attributes and yield an empty string.
+func getAttrValue(v interface{}, attr string) string {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return formatAttr(val[attr])
+	case []interface{}:
+		var values []string
+		for _, item := range val {
+			if m, ok := item.(map[string]interface{}); ok {
+				if value := formatAttr(m[attr]); value != "" {
+					values = append(values, value)
+				}
+			}
+		}
+		return strings.Join(values, ", ")
+	}
+	return ""
+}
+
+// formatAttr converts a decoded JSON attribute to a string
+func formatAttr(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package tracker
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNextPageURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		links []string
+		want  string
+	}{
+		{
+			name:  "no Link header",
+			links: nil,
+			want:  "",
+		},
+		{
+			name:  "next and self links",
+			links: []string{`<https://api.tracker.yandex.net/v2/issues/TEST-1/changelog?id=5f&perPage=50>; rel="next", <https://api.tracker.yandex.net/v2/issues/TEST-1/changelog?perPage=50>; rel="self"`},
+			want:  "https://api.tracker.yandex.net/v2/issues/TEST-1/changelog?id=5f&perPage=50",
+		},
+		{
+			name:  "next link after first link",
+			links: []string{`<https://api.tracker.yandex.net/v2/boards?page=1>; rel="first", <https://api.tracker.yandex.net/v2/boards?page=2>; rel="next"`},
+			want:  "https://api.tracker.yandex.net/v2/boards?page=2",
+		},
+		{
+			name:  "unquoted rel in a separate header",
+			links: []string{`<https://api.tracker.yandex.net/v2/boards?page=1>; rel=self`, `<https://api.tracker.yandex.net/v2/boards?page=2>; rel=next`},
+			want:  "https://api.tracker.yandex.net/v2/boards?page=2",
+		},
+		{
+			name:  "last page",
+			links: []string{`<https://api.tracker.yandex.net/v2/boards?page=1>; rel="first", <https://api.tracker.yandex.net/v2/boards?page=3>; rel="self"`},
+			want:  "",
+		},
+		{
+			name:  "malformed link",
+			links: []string{`https://api.tracker.yandex.net/v2/boards?page=2`},
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for _, link := range tt.links {
+				header.Add("Link", link)
+			}
+			if got := nextPageURL(header); got != tt.want {
+				t.Errorf("nextPageURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package tracker
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// SLA violation statuses
+const (
+	SLANotViolated           = "NOT_VIOLATED"
+	SLAWarnThresholdExceeded = "WARN_THRESHOLD_EXCEEDED"
+	SLAFailThresholdExceeded = "FAIL_THRESHOLD_EXCEEDED"
+)
+
+// SLATimer represents an SLA timer of an issue
+type SLATimer struct {
+	ID                     string `json:"id"`
+	SettingsID             string `json:"settingsId"`
+	ClockStatus            string `json:"clockStatus"`
+	ViolationStatus        string `json:"violationStatus"`
+	WarnThreshold          int64  `json:"warnThreshold"`
+	FailThreshold          int64  `json:"failThreshold"`
+	WarnAt                 Time   `json:"warnAt"`
+	FailAt                 Time   `json:"failAt"`
+	FailedAt               Time   `json:"failedAt"`
+	StartedAt              Time   `json:"startedAt"`
+	PausedAt               Time   `json:"pausedAt"`
+	PausedDuration         int64  `json:"pausedDuration"`
+	ToFailTimeWorkDuration int64  `json:"toFailTimeWorkDuration"`
+	Spent                  int64  `json:"spent"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Timer and settings
+// IDs may be returned as numbers or strings.
+func (t *SLATimer) UnmarshalJSON(data []byte) error {
+	type Alias SLATimer
+	aux := &struct {
+		ID         interface{} `json:"id"`
+		SettingsID interface{} `json:"settingsId"`
+		*Alias
+	}{
+		Alias: (*Alias)(t),
+	}
+	// Decode numbers as json.Number so that large IDs keep every digit
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&aux); err != nil {
+		return err
+	}
+
+	t.ID = idString(aux.ID)
+	t.SettingsID = idString(aux.SettingsID)
+	return nil
+}
+
+// Deadline returns when the timer fails
+func (t SLATimer) Deadline() Time {
+	return t.FailAt
+}
+
+// Failed reports whether the timer exceeded its fail threshold
+func (t SLATimer) Failed() bool {
+	return t.ViolationStatus == SLAFailThresholdExceeded
+}
+
+// idString converts a JSON ID decoded into an interface to a string
+func idString(id interface{}) string {
+	switch v := id.(type) {
+	case json.Number:
+		return v.String()
+	case string:
+		return v
+	default:
+		return ""
+	}
+}
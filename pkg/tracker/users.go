@@ -0,0 +1,49 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+// UserInfo represents a user of the organization returned by the users API
+type UserInfo struct {
+	Self        string `json:"self"`
+	UID         int64  `json:"uid"`
+	TrackerUID  int64  `json:"trackerUid"`
+	Login       string `json:"login"`
+	PassportUID int64  `json:"passportUid"`
+	CloudUID    string `json:"cloudUid"`
+	FirstName   string `json:"firstName"`
+	LastName    string `json:"lastName"`
+	Display     string `json:"display"`
+	Email       string `json:"email"`
+	External    bool   `json:"external"`
+	Dismissed   bool   `json:"dismissed"`
+
+	// Additional fields for storage
+	OrganizationID string `json:"organization_id"`
+	ID             string `json:"id"`
+}
+
+// GetUsers fetches all users of the organization from the Tracker API
+func (s *Service) GetUsers(ctx context.Context) ([]UserInfo, error) {
+	users, err := getAllPages[UserInfo](ctx, s, "/users", "users")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	// Set organization ID and the ID used to reference users from issues
+	for i := range users {
+		users[i].OrganizationID = s.cfg.Tracker.OrgID
+		uid := users[i].UID
+		if uid == 0 {
+			uid = users[i].TrackerUID
+		}
+		users[i].ID = strconv.FormatInt(uid, 10)
+	}
+
+	slog.Info("Successfully retrieved all users", "total_users", len(users))
+	return users, nil
+}
@@ -0,0 +1,62 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/time/rate"
+)
+
+// Workflow represents a queue workflow with its statuses and transitions
+type Workflow struct {
+	Self          string         `json:"self"`
+	ID            string         `json:"id"`
+	Version       int            `json:"version"`
+	Name          string         `json:"name"`
+	InitialStatus Field          `json:"initialStatus"`
+	IssueTypes    []Field        `json:"issueTypes"`
+	Steps         []WorkflowStep `json:"steps"`
+
+	// Additional fields for storage
+	OrganizationID string `json:"organization_id"`
+	QueueKey       string `json:"queue_key"`
+}
+
+// WorkflowStep represents a workflow status and the transitions leaving it
+type WorkflowStep struct {
+	Status      Field                `json:"status"`
+	Transitions []WorkflowTransition `json:"transitions"`
+}
+
+// WorkflowTransition represents a transition to another status
+type WorkflowTransition struct {
+	ID      string `json:"id"`
+	Display string `json:"display"`
+	To      Field  `json:"to"`
+}
+
+// GetWorkflows fetches the workflows of the given queues from the Tracker API,
+// retrying failed requests
+func (s *Service) GetWorkflows(ctx context.Context, queues []Queue) ([]Workflow, error) {
+	var workflows []Workflow
+	limiter := rate.NewLimiter(rate.Limit(20), 5)
+	for _, q := range queues {
+		var queueWorkflows []Workflow
+		path := "/queues/" + url.PathEscape(q.Key) + "/workflows"
+		if err := s.getJSONRetry(ctx, limiter, path, "queue_workflows", &queueWorkflows, attribute.String("tracker.queue", q.Key)); err != nil {
+			return nil, fmt.Errorf("failed to get workflows of queue %s: %w", q.Key, err)
+		}
+
+		for i := range queueWorkflows {
+			queueWorkflows[i].OrganizationID = s.cfg.Tracker.OrgID
+			queueWorkflows[i].QueueKey = q.Key
+		}
+		workflows = append(workflows, queueWorkflows...)
+	}
+
+	slog.Info("Successfully retrieved all workflows", "total_workflows", len(workflows))
+	return workflows, nil
+}
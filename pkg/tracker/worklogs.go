@@ -0,0 +1,95 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/time/rate"
+)
+
+// Worklog represents a time spent record of an issue
+type Worklog struct {
+	Self      string      `json:"self"`
+	ID        json.Number `json:"id"`
+	Comment   string      `json:"comment"`
+	CreatedBy User        `json:"createdBy"`
+	UpdatedBy User        `json:"updatedBy"`
+	CreatedAt Time        `json:"createdAt"`
+	UpdatedAt Time        `json:"updatedAt"`
+	Start     Time        `json:"start"`
+	Duration  string      `json:"duration"` // ISO 8601, e.g. PT1H30M
+
+	// Additional fields for storage
+	OrganizationID string `json:"organization_id"`
+	IssueKey       string `json:"issue_key"`
+}
+
+// GetWorklogsConcurrently retrieves the worklogs of multiple issues in
+// parallel with rate limiting. An issue whose worklogs cannot be fetched is
+// logged and returned in failed instead of failing the whole sync.
+func (s *Service) GetWorklogsConcurrently(ctx context.Context, issues []Issue, workers int) (worklogs []Worklog, failed []string, err error) {
+	limiter := rate.NewLimiter(rate.Limit(20), 5)
+	keys := make(chan string)
+	results := make(chan []Worklog, len(issues))
+	failures := make(chan string, len(issues))
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				issueWorklogs, err := s.getIssueWorklogs(ctx, limiter, key)
+				if err != nil {
+					if ctx.Err() == nil {
+						slog.Warn("Failed to fetch worklogs, skipping", "issue_key", key, "error", err)
+					}
+					failures <- key
+					continue
+				}
+				results <- issueWorklogs
+			}
+		}()
+	}
+
+	for _, issue := range issues {
+		keys <- issue.Key
+	}
+	close(keys)
+	wg.Wait()
+	close(results)
+	close(failures)
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	for issueWorklogs := range results {
+		worklogs = append(worklogs, issueWorklogs...)
+	}
+	for key := range failures {
+		failed = append(failed, key)
+	}
+
+	slog.Info("Finished fetching all worklogs", "total_worklogs", len(worklogs), "failed_issues", len(failed))
+	return worklogs, failed, nil
+}
+
+// getIssueWorklogs fetches the worklogs of an issue, retrying failed requests
+func (s *Service) getIssueWorklogs(ctx context.Context, limiter *rate.Limiter, issueKey string) ([]Worklog, error) {
+	var worklogs []Worklog
+	path := "/issues/" + url.PathEscape(issueKey) + "/worklog"
+	if err := s.getJSONRetry(ctx, limiter, path, "worklogs", &worklogs, attribute.String("tracker.issue_key", issueKey)); err != nil {
+		return nil, err
+	}
+
+	for i := range worklogs {
+		worklogs[i].OrganizationID = s.cfg.Tracker.OrgID
+		worklogs[i].IssueKey = issueKey
+	}
+	return worklogs, nil
+}